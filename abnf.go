@@ -0,0 +1,579 @@
+package pav
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ABNFParser is the grammar of RFC 5234 ABNF, with case-sensitive strings of RFC 7405
+type ABNFParser struct{}
+
+func (_ ABNFParser) Rulelist() *Instruction {
+	return OneOrMore(
+		Longest(
+			Named("Rule"),
+			// empty line
+			Seq(
+				ZeroOrMore(
+					Named("WSP"),
+				),
+				Named("CNl"),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) Rule() *Instruction {
+	return Seq(
+		Named("Rulename"),
+		Named("DefinedAs"),
+		Named("Alternation"),
+		// no continuation lines at the end of rule
+		ZeroOrMore(
+			Named("WSP"),
+		),
+		Named("CNl"),
+	)
+}
+
+func (_ ABNFParser) Rulename() *Instruction {
+	return Seq(
+		Named("ALPHA"),
+		ZeroOrMore(
+			Longest(
+				Named("ALPHA"),
+				Named("DIGIT"),
+				Rune('-'),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) DefinedAs() *Instruction {
+	return Seq(
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+		Longest(
+			Literal("="),
+			Literal("=/"),
+		),
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+	)
+}
+
+func (_ ABNFParser) CWsp() *Instruction {
+	return Longest(
+		Named("WSP"),
+		Seq(
+			Named("CNl"),
+			Named("WSP"),
+		),
+	)
+}
+
+func (_ ABNFParser) CNl() *Instruction {
+	return Longest(
+		Named("Comment"),
+		Named("Newline"),
+	)
+}
+
+func (_ ABNFParser) Comment() *Instruction {
+	return Seq(
+		Rune(';'),
+		ZeroOrMore(
+			RuneInverse(RuneSet('\r', '\n')),
+		),
+		Named("Newline"),
+	)
+}
+
+func (_ ABNFParser) Newline() *Instruction {
+	return Longest(
+		Literal("\r\n"),
+		Rune('\n'),
+	)
+}
+
+func (_ ABNFParser) Alternation() *Instruction {
+	return Seq(
+		Named("Concatenation"),
+		ZeroOrMore(
+			Seq(
+				ZeroOrMore(
+					Named("CWsp"),
+				),
+				Rune('/'),
+				ZeroOrMore(
+					Named("CWsp"),
+				),
+				Named("Concatenation"),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) Concatenation() *Instruction {
+	return Seq(
+		Named("Repetition"),
+		ZeroOrMore(
+			Seq(
+				OneOrMore(
+					Named("CWsp"),
+				),
+				Named("Repetition"),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) Repetition() *Instruction {
+	return Seq(
+		Optional(
+			Named("Repeat"),
+		),
+		Longest(
+			Named("Rulename"),
+			Named("Group"),
+			Named("Option"),
+			Named("CharVal"),
+			Named("NumVal"),
+			Named("ProseVal"),
+		),
+	)
+}
+
+func (_ ABNFParser) Repeat() *Instruction {
+	return Longest(
+		OneOrMore(
+			Named("DIGIT"),
+		),
+		Seq(
+			ZeroOrMore(
+				Named("DIGIT"),
+			),
+			Rune('*'),
+			ZeroOrMore(
+				Named("DIGIT"),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) Group() *Instruction {
+	return Seq(
+		Rune('('),
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+		Named("Alternation"),
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+		Rune(')'),
+	)
+}
+
+func (_ ABNFParser) Option() *Instruction {
+	return Seq(
+		Rune('['),
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+		Named("Alternation"),
+		ZeroOrMore(
+			Named("CWsp"),
+		),
+		Rune(']'),
+	)
+}
+
+func (_ ABNFParser) CharVal() *Instruction {
+	return Seq(
+		Optional(
+			Longest(
+				Literal("%s"),
+				Literal("%i"),
+			),
+		),
+		Rune('"'),
+		ZeroOrMore(
+			Longest(
+				RuneRange(0x20, 0x21),
+				RuneRange(0x23, 0x7e),
+			),
+		),
+		Rune('"'),
+	)
+}
+
+func (_ ABNFParser) NumVal() *Instruction {
+	return Seq(
+		Rune('%'),
+		Longest(
+			Seq(
+				RuneSet('b', 'B'),
+				Named("Digits"),
+			),
+			Seq(
+				RuneSet('d', 'D'),
+				Named("Digits"),
+			),
+			Seq(
+				RuneSet('x', 'X'),
+				Named("Digits"),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) Digits() *Instruction {
+	// checked by the radix when compiling
+	digits := OneOrMore(
+		Named("HEXDIG"),
+	)
+	return Seq(
+		digits,
+		Optional(
+			Longest(
+				OneOrMore(
+					Seq(
+						Rune('.'),
+						digits,
+					),
+				),
+				Seq(
+					Rune('-'),
+					digits,
+				),
+			),
+		),
+	)
+}
+
+func (_ ABNFParser) ProseVal() *Instruction {
+	return Seq(
+		Rune('<'),
+		ZeroOrMore(
+			Longest(
+				RuneRange(0x20, 0x3d),
+				RuneRange(0x3f, 0x7e),
+			),
+		),
+		Rune('>'),
+	)
+}
+
+func (_ ABNFParser) ALPHA() *Instruction {
+	return Longest(
+		RuneRange('A', 'Z'),
+		RuneRange('a', 'z'),
+	)
+}
+
+func (_ ABNFParser) DIGIT() *Instruction {
+	return RuneRange('0', '9')
+}
+
+func (_ ABNFParser) HEXDIG() *Instruction {
+	return Longest(
+		RuneRange('0', '9'),
+		RuneRange('A', 'F'),
+		RuneRange('a', 'f'),
+	)
+}
+
+func (_ ABNFParser) WSP() *Instruction {
+	return RuneSet(' ', '\t')
+}
+
+const abnfCoreRules = `
+ALPHA = %x41-5A / %x61-7A
+BIT = "0" / "1"
+CHAR = %x01-7F
+CR = %x0D
+CRLF = CR LF
+CTL = %x00-1F / %x7F
+DIGIT = %x30-39
+DQUOTE = %x22
+HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+HTAB = %x09
+LF = %x0A
+LWSP = *(WSP / CRLF WSP)
+OCTET = %x00-FF
+SP = %x20
+VCHAR = %x21-7E
+WSP = SP / HTAB
+`
+
+// CompileABNF compiles ABNF rules to routines, with the core rules of RFC 5234 predefined.
+// Rule names are case-insensitive, routines are named by the first definitions.
+// Alternatives compile to Longest, prose values are not supported.
+func CompileABNF(src string) (map[string]Routine, error) {
+	core, err := compileABNF(abnfCoreRules, nil)
+	if err != nil { // NOCOVER
+		panic(err)
+	}
+	return compileABNF(src, core)
+}
+
+func compileABNF(src string, predefined map[string]Routine) (map[string]Routine, error) {
+	runes, tree, err := parseWithCapture(new(ABNFParser), "Rulelist", src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &abnfCompiler{
+		src:   runes,
+		names: make(map[string]string),
+	}
+	// resolve rule names before compiling, references are case-insensitive
+	var rules []*Node
+	for _, rule := range tree.Children {
+		if rule.Name != "Rule" {
+			continue
+		}
+		name := c.text(rule.Find("Rulename"))
+		key := strings.ToLower(name)
+		incremental := strings.Contains(c.text(rule.Find("DefinedAs")), "=/")
+		if _, ok := c.names[key]; ok {
+			if !incremental {
+				return nil, fmt.Errorf("%s: duplicated definition of %s", runePos(runes, rule.Start), name)
+			}
+		} else {
+			if incremental {
+				return nil, fmt.Errorf("%s: incremental alternatives to undefined rule %s", runePos(runes, rule.Start), name)
+			}
+			c.names[key] = name
+		}
+		rules = append(rules, rule)
+	}
+	routines := make(map[string]Routine)
+	for name, routine := range predefined {
+		key := strings.ToLower(name)
+		if _, ok := c.names[key]; ok {
+			// redefined
+			continue
+		}
+		c.names[key] = name
+		routines[name] = routine
+	}
+
+	alts := make(map[string][]*Instruction)
+	for _, rule := range rules {
+		name := c.names[strings.ToLower(c.text(rule.Find("Rulename")))]
+		inst, err := c.alternation(rule.Find("Alternation"))
+		if err != nil {
+			return nil, err
+		}
+		alts[name] = append(alts[name], inst)
+	}
+	for name, insts := range alts {
+		inst := insts[0]
+		if len(insts) > 1 {
+			inst = Longest(insts...)
+		}
+		routines[name] = Routine{
+			Start: inst,
+		}
+	}
+
+	return routines, nil
+}
+
+type abnfCompiler struct {
+	src []rune
+	// canonical rule names by lower case names
+	names map[string]string
+}
+
+func (c *abnfCompiler) text(n *Node) string {
+	return string(c.src[n.Start:n.End])
+}
+
+func (c *abnfCompiler) alternation(n *Node) (*Instruction, error) {
+	var insts []*Instruction
+	for _, child := range n.Children {
+		if child.Name != "Concatenation" {
+			continue
+		}
+		inst, err := c.concatenation(child)
+		if err != nil {
+			return nil, err
+		}
+		insts = append(insts, inst)
+	}
+	if len(insts) == 1 {
+		return insts[0], nil
+	}
+	return Longest(insts...), nil
+}
+
+func (c *abnfCompiler) concatenation(n *Node) (*Instruction, error) {
+	var insts []*Instruction
+	for _, child := range n.Children {
+		if child.Name != "Repetition" {
+			continue
+		}
+		inst, err := c.repetition(child)
+		if err != nil {
+			return nil, err
+		}
+		insts = append(insts, inst)
+	}
+	if len(insts) == 1 {
+		return insts[0], nil
+	}
+	return Seq(insts...), nil
+}
+
+func (c *abnfCompiler) repetition(n *Node) (*Instruction, error) {
+	var repeat *Node
+	var inst *Instruction
+	for _, child := range n.Children {
+		var err error
+		switch child.Name {
+		case "Repeat":
+			repeat = child
+			continue
+		case "Rulename":
+			name, ok := c.names[strings.ToLower(c.text(child))]
+			if !ok {
+				return nil, fmt.Errorf("%s: undefined rule %s", runePos(c.src, child.Start), c.text(child))
+			}
+			inst = Named(name)
+		case "Group":
+			inst, err = c.alternation(child.Find("Alternation"))
+		case "Option":
+			inst, err = c.alternation(child.Find("Alternation"))
+			if err == nil {
+				inst = Optional(inst)
+			}
+		case "CharVal":
+			inst = c.charVal(child)
+		case "NumVal":
+			inst, err = c.numVal(child)
+		case "ProseVal":
+			err = fmt.Errorf("%s: prose value not supported", runePos(c.src, child.Start))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if repeat == nil {
+		return inst, nil
+	}
+
+	// n*m
+	text := c.text(repeat)
+	min, max := 0, -1
+	var err error
+	if i := strings.Index(text, "*"); i < 0 {
+		min, err = strconv.Atoi(text)
+		max = min
+	} else {
+		if i > 0 {
+			min, err = strconv.Atoi(text[:i])
+		}
+		if err == nil && i < len(text)-1 {
+			max, err = strconv.Atoi(text[i+1:])
+		}
+	}
+	if err != nil || max >= 0 && max < min {
+		return nil, fmt.Errorf("%s: bad repeat", runePos(c.src, repeat.Start))
+	}
+	var insts []*Instruction
+	for i := 0; i < min; i++ {
+		insts = append(insts, inst)
+	}
+	if max < 0 {
+		insts = append(insts, ZeroOrMore(inst))
+	} else if max > min {
+		var optional *Instruction
+		for i := min; i < max; i++ {
+			if optional == nil {
+				optional = Optional(inst)
+			} else {
+				optional = Optional(Seq(inst, optional))
+			}
+		}
+		insts = append(insts, optional)
+	}
+	if len(insts) == 0 {
+		return emptyInstruction(), nil
+	} else if len(insts) == 1 {
+		return insts[0], nil
+	}
+	return Seq(insts...), nil
+}
+
+func (c *abnfCompiler) charVal(n *Node) *Instruction {
+	text := c.text(n)
+	caseSensitive := strings.HasPrefix(text, "%s")
+	text = text[strings.Index(text, `"`)+1 : len(text)-1]
+	if len(text) == 0 {
+		return emptyInstruction()
+	}
+	if caseSensitive {
+		return Literal(text)
+	}
+	var insts []*Instruction
+	for _, r := range text {
+		if lower, upper := unicode.ToLower(r), unicode.ToUpper(r); lower != upper {
+			insts = append(insts, RuneSet(lower, upper))
+		} else {
+			insts = append(insts, Rune(r))
+		}
+	}
+	if len(insts) == 1 {
+		return insts[0]
+	}
+	return Seq(insts...)
+}
+
+func (c *abnfCompiler) numVal(n *Node) (*Instruction, error) {
+	text := c.text(n)
+	base := 16
+	switch text[1] {
+	case 'b', 'B':
+		base = 2
+	case 'd', 'D':
+		base = 10
+	}
+	parse := func(s string) (rune, error) {
+		r, err := strconv.ParseUint(s, base, 32)
+		if err != nil || r > unicode.MaxRune {
+			return 0, fmt.Errorf("%s: bad value", runePos(c.src, n.Start))
+		}
+		return rune(r), nil
+	}
+	digits := text[2:]
+	if i := strings.Index(digits, "-"); i >= 0 {
+		r1, err := parse(digits[:i])
+		if err != nil {
+			return nil, err
+		}
+		r2, err := parse(digits[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		if r1 > r2 {
+			return nil, fmt.Errorf("%s: bad range", runePos(c.src, n.Start))
+		}
+		return runeRanges([][2]rune{{r1, r2}}, false), nil
+	}
+	var runes []rune
+	for _, s := range strings.Split(digits, ".") {
+		r, err := parse(s)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+	return RuneSeq(runes), nil
+}
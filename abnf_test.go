@@ -0,0 +1,204 @@
+package pav
+
+import "testing"
+
+func TestABNF(t *testing.T) {
+	// from RFC 3986
+	routines, err := CompileABNF(`
+URI           = scheme ":" hier-part [ "?" query ] [ "#" fragment ]
+
+hier-part     = "//" authority path-abempty
+              / path-absolute
+              / path-rootless
+              / path-empty
+
+scheme        = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )
+
+authority     = [ userinfo "@" ] host [ ":" port ]
+userinfo      = *( unreserved / pct-encoded / sub-delims / ":" )
+host          = IPv4address / reg-name
+port          = *DIGIT
+
+IPv4address   = dec-octet "." dec-octet "." dec-octet "." dec-octet
+dec-octet     = DIGIT                 ; 0-9
+              / %x31-39 DIGIT         ; 10-99
+              / "1" 2DIGIT            ; 100-199
+              / "2" %x30-34 DIGIT     ; 200-249
+              / "25" %x30-35          ; 250-255
+reg-name      = *( unreserved / pct-encoded / sub-delims )
+
+path-abempty  = *( "/" segment )
+path-absolute = "/" [ segment-nz *( "/" segment ) ]
+path-rootless = segment-nz *( "/" segment )
+path-empty    = 0<pchar>
+segment       = *pchar
+segment-nz    = 1*pchar
+pchar         = unreserved / pct-encoded / sub-delims / ":" / "@"
+
+query         = *( pchar / "/" / "?" )
+fragment      = *( pchar / "/" / "?" )
+
+pct-encoded   = "%" HEXDIG HEXDIG
+unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
+sub-delims    = "!" / "$" / "&" / "'" / "(" / ")"
+              / "*" / "+" / "," / ";" / "="
+`)
+	if err == nil {
+		t.Fatal("should fail on prose value")
+	}
+	eq(t,
+		err.Error(), "27:18: prose value not supported",
+	)
+
+	routines, err = CompileABNF(`
+URI           = scheme ":" hier-part [ "?" query ]
+hier-part     = "//" authority path-abempty
+              / path-rootless
+scheme        = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )
+authority     = host [ ":" port ]
+host          = IPv4address / reg-name
+port          = *DIGIT
+IPv4address   = dec-octet "." dec-octet "." dec-octet "." dec-octet
+dec-octet     = DIGIT                 ; 0-9
+              / %x31-39 DIGIT         ; 10-99
+              / "1" 2DIGIT            ; 100-199
+              / "2" %x30-34 DIGIT     ; 200-249
+              / "25" %x30-35          ; 250-255
+reg-name      = 1*( unreserved / pct-encoded )
+path-abempty  = *( "/" segment )
+path-rootless = 1*pchar *( "/" segment )
+segment       = *pchar
+pchar         = unreserved / pct-encoded / sub-delims / ":" / "@"
+query         = *( pchar / "/" / "?" )
+pct-encoded   = "%" HEXDIG HEXDIG
+unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
+sub-delims    = "!" / "$" / "&" / "'" / "(" / ")"
+              / "*" / "+" / "," / ";" / "="
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{
+		"http://example.com",
+		"HTTP://example.com:8080/a/b%2F?q=1",
+		"ftp://192.168.0.255/",
+		"mailto:a@b",
+		"urn:isbn:0451450523",
+	} {
+		vm := NewVM(routines, Named("URI"))
+		if !match(vm, input) {
+			t.Errorf("should match: %s", input)
+		}
+	}
+	for _, input := range []string{
+		"1http://example.com",
+		"http://exa mple.com",
+		"http://a/%zz",
+		"http",
+	} {
+		vm := NewVM(routines, Named("URI"))
+		if match(vm, input) {
+			t.Errorf("should not match: %s", input)
+		}
+	}
+	vm := NewVM(routines, Named("IPv4address"))
+	eq(t,
+		match(vm, "256.1.1.1"), false,
+	)
+}
+
+func TestABNFRepetition(t *testing.T) {
+	routines, err := CompileABNF(`
+a = 2*3"a"
+b = *2%s"B" %d98.99
+c = 3%b1100001
+d = 1*HEXDIG
+e = "x" 0"y" ""
+f = "a"
+f =/ "b" ; incremental
+f =/ "c"
+ "d" 
+g = ( "a"
+      "b" ) [ %x63-65 ]
+h = LWSP
+i = "x" ("y" / "")
+j = "x" ("y" / *"z") ["w" / ""]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name  string
+		input string
+		ok    bool
+	}{
+		{"a", "a", false},
+		{"a", "aA", true},
+		{"a", "aaa", true},
+		{"a", "aaaa", false},
+		{"b", "bc", true},
+		{"b", "BBbc", true},
+		{"b", "bbbc", false},
+		{"b", "BBBbc", false},
+		{"c", "aaa", true},
+		{"c", "AAA", false},
+		{"d", "0aF9", true},
+		{"d", "g", false},
+		{"e", "X", true},
+		{"e", "xy", false},
+		{"f", "a", true},
+		{"f", "b", true},
+		{"f", "c", false},
+		{"f", "cd", true},
+		{"g", "AB", true},
+		{"g", "abd", true},
+		{"g", "abf", false},
+		{"h", " \r\n\t", true},
+		{"i", "x", true},
+		{"i", "xy", true},
+		{"i", "xz", false},
+		{"j", "x", true},
+		{"j", "xzz", true},
+		{"j", "xyw", true},
+		{"j", "xw", true},
+	} {
+		vm := NewVM(routines, Named(c.name))
+		if match(vm, c.input) != c.ok {
+			t.Errorf("%s %q", c.name, c.input)
+		}
+	}
+}
+
+func TestABNFRuleNames(t *testing.T) {
+	routines, err := CompileABNF("Foo = bar digit\nBAR = \"x\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// references are named by the first definitions
+	eq(t,
+		routines["Foo"].Start.Inst.Name, "BAR",
+		match(NewVM(routines, Named("Foo")), "x1"), true,
+	)
+}
+
+func TestABNFError(t *testing.T) {
+	for src, msg := range map[string]string{
+		"a = b":                "1:5: undefined rule b",
+		"a = \"a\"\nb = a c":   "2:7: undefined rule c",
+		"a = \"a\"\nA = \"b\"": "2:1: duplicated definition of A",
+		"a =/ \"a\"":           "1:1: incremental alternatives to undefined rule a",
+		"a = 3*2\"a\"":         "1:5: bad repeat",
+		"a = %x5A-41":          "1:5: bad range",
+		"a = %d1a":             "1:5: bad value",
+		"a = \"a\" \"b":        "1:11: unexpected end of input",
+		" a = \"a\"":           "1:2: syntax error",
+	} {
+		_, err := CompileABNF(src)
+		if err == nil {
+			t.Errorf("%q: should fail", src)
+			continue
+		}
+		eq(t,
+			err.Error(), msg,
+		)
+	}
+}
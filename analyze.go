@@ -0,0 +1,369 @@
+package pav
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FindingKind is the kind of a grammar issue
+type FindingKind uint8
+
+const (
+	// the body of a repetition matches the empty input, the thread loops until killed by the bound
+	NullableRepetition FindingKind = iota + 1
+	// the rule calls itself before consuming any rune
+	DirectLeftRecursion
+	// the rule calls itself through other rules before consuming any rune
+	IndirectLeftRecursion
+	// the rule calls a name not in the routines
+	UndefinedName
+	// the rule is not reachable from the start rules
+	UnreachableRule
+	// an earlier alternative of First or Shortest always finishes no later than the alternative
+	ShadowedAlternative
+	// the rule calls a method with parameters by name, which is not a routine
+	HelperReference
+)
+
+// Finding is a grammar issue reported by Analyze
+type Finding struct {
+	Kind FindingKind
+	Rule string
+	// the instruction of the repetition or the call
+	Inst *Instruction
+	// rule names of the left recursion, starting and ending with Rule
+	Cycle []string
+	// the undefined name or the helper method name
+	Name string
+	// index of the shadowed alternative and the shadowing alternative
+	Alternative int
+	ShadowedBy  int
+}
+
+func (f Finding) String() string {
+	s := f.Kind.String() + " in " + f.Rule
+	switch f.Kind {
+	case IndirectLeftRecursion:
+		s += ": " + strings.Join(f.Cycle, " -> ")
+	case UndefinedName, HelperReference:
+		s += ": " + f.Name
+	case ShadowedAlternative:
+		s += fmt.Sprintf(": %d by %d", f.Alternative, f.ShadowedBy)
+	}
+	if f.Inst != nil && f.Inst.file != "" {
+		s += " at " + f.Inst.Pos()
+	}
+	return s
+}
+
+// Analysis holds the nullability and FIRST sets of instructions in routines.
+// Instructions not in routines are analyzed when queried, so methods are not safe for concurrent use.
+type Analysis struct {
+	Routines map[string]Routine
+	// findings sorted by rule name
+	Findings []Finding
+
+	nodes []*analysisNode
+	infos map[*Instruction]*analysisNode
+	// rule names of instructions in routines
+	owners map[*Instruction]string
+}
+
+type analysisNode struct {
+	inst     *Instruction
+	index    int
+	nullable bool
+	// rune instructions that may consume the first rune
+	first map[*Instruction]bool
+	// first in a slice, built by mayStart
+	firstList []*Instruction
+	// names of rules that may be called directly before consuming any rune
+	left map[string]bool
+}
+
+// Analyze computes nullability, FIRST sets, left recursions and nullable repetitions of routines
+func Analyze(routines map[string]Routine) *Analysis {
+	a := &Analysis{
+		Routines: routines,
+		infos:    make(map[*Instruction]*analysisNode),
+		owners:   make(map[*Instruction]string),
+	}
+	names := make([]string, 0, len(routines))
+	for name := range routines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		start := len(a.nodes)
+		a.add(routines[name].Start)
+		for _, node := range a.nodes[start:] {
+			a.owners[node.inst] = name
+		}
+	}
+	a.solve()
+
+	for _, node := range a.nodes {
+		inst := node.inst
+		if body := zeroOrMoreBody(inst); body != nil && a.Nullable(body) {
+			a.Findings = append(a.Findings, Finding{
+				Kind: NullableRepetition,
+				Rule: a.owners[inst],
+				Inst: inst,
+			})
+		}
+		if inst.Op == OpCall && inst.Inst == nil && inst.Name != "" {
+			if _, ok := routines[inst.Name]; !ok {
+				a.Findings = append(a.Findings, Finding{
+					Kind: UndefinedName,
+					Rule: a.owners[inst],
+					Inst: inst,
+					Name: inst.Name,
+				})
+			}
+		}
+	}
+
+	for _, name := range names {
+		left := a.node(routines[name].Start).left
+		if left[name] {
+			a.Findings = append(a.Findings, Finding{
+				Kind: DirectLeftRecursion,
+				Rule: name,
+			})
+		} else if cycle := a.leftCycle(name); cycle != nil {
+			a.Findings = append(a.Findings, Finding{
+				Kind:  IndirectLeftRecursion,
+				Rule:  name,
+				Cycle: cycle,
+			})
+		}
+	}
+
+	sort.SliceStable(a.Findings, func(i, j int) bool {
+		return a.Findings[i].Rule < a.Findings[j].Rule
+	})
+	return a
+}
+
+// leftCycle returns the shortest left calling path from the rule to itself
+func (a *Analysis) leftCycle(name string) []string {
+	from := map[string]string{
+		name: "",
+	}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		r, ok := a.Routines[current]
+		if !ok {
+			continue
+		}
+		left := a.node(r.Start).left
+		callees := make([]string, 0, len(left))
+		for callee := range left {
+			callees = append(callees, callee)
+		}
+		sort.Strings(callees)
+		for _, callee := range callees {
+			if callee == name {
+				cycle := []string{name}
+				for n := current; n != name; n = from[n] {
+					cycle = append([]string{n}, cycle...)
+				}
+				return append([]string{name}, cycle...)
+			}
+			if _, ok := from[callee]; !ok {
+				from[callee] = current
+				queue = append(queue, callee)
+			}
+		}
+	}
+	return nil
+}
+
+// Nullable reports whether the instruction may return without consuming any rune
+func (a *Analysis) Nullable(inst *Instruction) bool {
+	return a.node(inst).nullable
+}
+
+// First returns the rune instructions that may consume the first rune matched by the instruction
+func (a *Analysis) First(inst *Instruction) []*Instruction {
+	node := a.node(inst)
+	ret := make([]*Instruction, 0, len(node.first))
+	for i := range node.first {
+		ret = append(ret, i)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return a.infos[ret[i]].index < a.infos[ret[j]].index
+	})
+	return ret
+}
+
+// mayStart reports whether the instruction may return without consuming any rune, or consume r first
+func (a *Analysis) mayStart(inst *Instruction, r rune) bool {
+	node := a.node(inst)
+	if node.nullable {
+		return true
+	}
+	if node.firstList == nil {
+		node.firstList = a.First(inst)
+	}
+	for _, i := range node.firstList {
+		if matchRune(i, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare builds FIRST lists of all added instructions, so queries of them do not modify the analysis
+func (a *Analysis) prepare() {
+	for _, node := range a.nodes {
+		if node.firstList == nil {
+			node.firstList = a.First(node.inst)
+		}
+	}
+}
+
+var nilAnalysisNode = &analysisNode{
+	nullable: true,
+}
+
+func (a *Analysis) node(inst *Instruction) *analysisNode {
+	if inst == nil {
+		return nilAnalysisNode
+	}
+	if node, ok := a.infos[inst]; ok {
+		return node
+	}
+	// not in routines
+	a.add(inst)
+	a.solve()
+	return a.infos[inst]
+}
+
+func (a *Analysis) add(inst *Instruction) {
+	walkInstructions(inst, nil, func(i *Instruction) bool {
+		if _, ok := a.infos[i]; ok {
+			return false
+		}
+		node := &analysisNode{
+			inst:  i,
+			index: len(a.nodes),
+			first: make(map[*Instruction]bool),
+			left:  make(map[string]bool),
+		}
+		a.infos[i] = node
+		a.nodes = append(a.nodes, node)
+		return true
+	})
+}
+
+// solve iterates to the least fixed point
+func (a *Analysis) solve() {
+	for changed := true; changed; {
+		changed = false
+		for i := len(a.nodes) - 1; i >= 0; i-- {
+			if a.update(a.nodes[i]) {
+				changed = true
+			}
+		}
+	}
+}
+
+// update recomputes the node from its successors, returns true if changed
+func (a *Analysis) update(node *analysisNode) bool {
+	inst := node.inst
+	nullable := false
+	nFirst := len(node.first)
+	nLeft := len(node.left)
+	merge := func(n *analysisNode) {
+		for i := range n.first {
+			node.first[i] = true
+		}
+		for name := range n.left {
+			node.left[name] = true
+		}
+	}
+	// seq merges the node followed by the continuation
+	seq := func(n, cont *analysisNode) bool {
+		merge(n)
+		if n.nullable {
+			merge(cont)
+			return cont.nullable
+		}
+		return false
+	}
+
+	switch inst.Op {
+
+	case OpRune:
+		if inst.Predict {
+			next := a.lookup(inst.Inst)
+			merge(next)
+			nullable = next.nullable
+		} else {
+			node.first[inst] = true
+		}
+
+	case OpCall:
+		var callee *analysisNode
+		if inst.Inst != nil {
+			callee = a.lookup(inst.Inst)
+		} else if r, ok := a.Routines[inst.Name]; ok {
+			node.left[inst.Name] = true
+			// left calls of the routine are not merged to keep direct calls only
+			start := a.lookup(r.Start)
+			callee = &analysisNode{
+				nullable: start.nullable,
+				first:    start.first,
+			}
+		} else {
+			// undefined
+			callee = new(analysisNode)
+		}
+		nullable = seq(callee, a.lookup(inst.Next))
+
+	case OpJump:
+		next := a.lookup(inst.Inst)
+		merge(next)
+		nullable = next.nullable
+
+	case OpClone:
+		next := a.lookup(inst.Next)
+		for _, branch := range inst.Insts {
+			if branch == nil {
+				merge(next)
+				nullable = nullable || next.nullable
+			} else if seq(a.lookup(branch), next) {
+				nullable = true
+			}
+		}
+
+	case OpReturn:
+		nullable = true
+
+	case OpIndirect:
+		next := a.lookup(*inst.InstP)
+		merge(next)
+		nullable = next.nullable
+
+	}
+
+	changed := nullable && !node.nullable || len(node.first) != nFirst || len(node.left) != nLeft
+	node.nullable = node.nullable || nullable
+	return changed
+}
+
+// lookup returns the node of an added instruction
+func (a *Analysis) lookup(inst *Instruction) *analysisNode {
+	if inst == nil {
+		return nilAnalysisNode
+	}
+	node, ok := a.infos[inst]
+	if !ok { // NOCOVER
+		panic(fmt.Sprintf("instruction not added: %s", inst))
+	}
+	return node
+}
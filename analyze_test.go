@@ -0,0 +1,85 @@
+package pav
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	routines := map[string]Routine{
+		"Blank": {
+			Start: ZeroOrMore(Optional(Rune(' '))),
+		},
+		"Expr": {
+			Start: First(
+				Seq(Named("Expr"), Rune('+'), Named("Term")),
+				Named("Term"),
+			),
+		},
+		"Term": {
+			Start: First(
+				Seq(Named("Factor"), Rune('*'), Named("Digit")),
+				Named("Digit"),
+			),
+		},
+		"Factor": {
+			Start: Seq(Named("Blank"), Named("Term")),
+		},
+		"Digit": {
+			Start: Seq(RuneRange('0', '9'), Named("Digits")),
+		},
+	}
+	a := Analyze(routines)
+	var findings []string
+	for _, f := range a.Findings {
+		findings = append(findings, f.String())
+	}
+	eq(t,
+		fmt.Sprintf("%q", findings),
+		fmt.Sprintf("%q", []string{
+			"NullableRepetition in Blank at analyze_test.go:11",
+			"UndefinedName in Digit: Digits at analyze_test.go:29",
+			"DirectLeftRecursion in Expr",
+			"IndirectLeftRecursion in Factor: Factor -> Term -> Factor",
+			"IndirectLeftRecursion in Term: Term -> Factor -> Term",
+		}),
+	)
+
+	eq(t,
+		a.Nullable(Named("Blank")), true,
+		a.Nullable(Named("Factor")), false,
+		a.Nullable(Optional(Named("Digit"))), true,
+		a.Nullable(nil), true,
+		a.Nullable(RunePredict(Rune('a'), nil)), true,
+		len(a.First(Named("Blank"))), 1,
+		len(a.First(Named("Digit"))), 1,
+		a.First(Named("Digit"))[0] == routines["Digit"].Start.Operands[0], true,
+		len(a.First(Named("Expr"))), 2,
+	)
+}
+
+func TestAnalyzeBuiltin(t *testing.T) {
+	for _, obj := range []interface{}{
+		new(JSONParser),
+		new(JSONCParser),
+		new(JSON5Parser),
+		new(TextGrammarParser),
+		new(GoLexer),
+	} {
+		a := Analyze(ObjectRoutines(obj))
+		for _, f := range a.Findings {
+			t.Errorf("%T: %s", obj, f)
+		}
+	}
+
+	a := Analyze(ObjectRoutines(new(JSONParser)))
+	var first []string
+	for _, inst := range a.First(Named("Number")) {
+		first = append(first, runeClass(inst))
+	}
+	eq(t,
+		fmt.Sprintf("%q", first), `["[\\-]" "[0]" "[1-9]"]`,
+		a.Nullable(Named("Blank")), true,
+		a.Nullable(Named("Value")), false,
+	)
+}
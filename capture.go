@@ -0,0 +1,95 @@
+package pav
+
+import "fmt"
+
+// Capture records the start or end of a named routine call, at a rune offset
+type Capture struct {
+	Prev  *Capture
+	Name  string
+	Pos   int
+	Start bool
+}
+
+// Node is a named routine call spanning runes [Start, End)
+type Node struct {
+	Name     string
+	Start    int
+	End      int
+	Children []*Node
+}
+
+// Tree returns a root node containing the top-level named calls of the thread.
+// VM.Capture must be set before stepping.
+func (t *Thread) Tree() *Node {
+	var captures []*Capture
+	for c := t.Captures; c != nil; c = c.Prev {
+		captures = append(captures, c)
+	}
+	root := new(Node)
+	stack := []*Node{root}
+	for i := len(captures) - 1; i >= 0; i-- {
+		c := captures[i]
+		if c.Start {
+			node := &Node{
+				Name:  c.Name,
+				Start: c.Pos,
+			}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		} else if len(stack) > 1 {
+			node := stack[len(stack)-1]
+			node.End = c.Pos
+			stack = stack[:len(stack)-1]
+		}
+		root.End = c.Pos
+	}
+	return root
+}
+
+// Find returns the first child with the name
+func (n *Node) Find(name string) *Node {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// parseWithCapture matches src with a newline appended from the named routine of obj,
+// and returns the runes matched and the node of the routine.
+// The sentinel newline terminates comments and names at the end of the source.
+func parseWithCapture(obj interface{}, start string, src string) ([]rune, *Node, error) {
+	runes := append([]rune(src), '\n')
+	vm := NewVMFromObject(obj, Named(start))
+	vm.Capture = true
+	var res StepResult
+	for i, r := range runes {
+		res = vm.Step(r)
+		if len(vm.Threads) == 0 && i < len(runes)-1 {
+			if len(res.Matched) > 0 {
+				i++
+			}
+			return nil, nil, fmt.Errorf("%s: syntax error", runePos(runes, i))
+		}
+	}
+	if len(res.Matched) == 0 {
+		return nil, nil, fmt.Errorf("%s: unexpected end of input", runePos(runes, len(src)))
+	}
+	return runes, res.Matched[0].Tree().Find(start), nil
+}
+
+// runePos formats the rune offset as line:column
+func runePos(runes []rune, offset int) string {
+	line, col := 1, 1
+	for _, r := range runes[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Sprintf("%d:%d", line, col)
+}
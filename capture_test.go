@@ -0,0 +1,58 @@
+package pav
+
+import "testing"
+
+func TestCapture(t *testing.T) {
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	vm.Capture = true
+	var res StepResult
+	for _, r := range ` [1, {"a": true}] ` {
+		res = vm.Step(r)
+	}
+	if len(res.Matched) != 1 {
+		t.Fatal("should match")
+	}
+
+	var dump func(n *Node) []interface{}
+	dump = func(n *Node) []interface{} {
+		ret := []interface{}{n.Name, n.Start, n.End}
+		for _, child := range n.Children {
+			if child.Name == "Blank" || child.Name == "HexDigit" {
+				continue
+			}
+			ret = append(ret, dump(child))
+		}
+		return ret
+	}
+	eq(t,
+		dump(res.Matched[0].Tree()), []interface{}{
+			"", 0, 18,
+			[]interface{}{
+				"Text", 0, 18,
+				[]interface{}{
+					"Value", 0, 17,
+					[]interface{}{
+						"Array", 0, 17,
+						[]interface{}{
+							"Value", 2, 3,
+							[]interface{}{"Number", 2, 3},
+						},
+						[]interface{}{
+							"Value", 4, 16,
+							[]interface{}{
+								"Object", 4, 16,
+								[]interface{}{"String", 6, 9},
+								[]interface{}{"Value", 10, 15},
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+
+	tree := res.Matched[0].Tree()
+	if tree.Find("Text") == nil || tree.Find("Value") != nil {
+		t.Fatal()
+	}
+}
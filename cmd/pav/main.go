@@ -0,0 +1,329 @@
+// Command pav matches files against grammars.
+//
+//	pav match -grammar json -start Value file.json
+//	pav trace -grammar json file.json
+//	pav tokens file.go
+//
+// The grammar is one of the builtin grammar objects, or a text grammar file with .pav or .abnf extension.
+// Files default to the standard input.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reusee/pav"
+	"github.com/reusee/pav/internal/grammars"
+)
+
+var commands = map[string]func(args []string, w io.Writer) error{
+	"match":  match,
+	"trace":  trace,
+	"tokens": tokens,
+}
+
+// errFailed indicates that some inputs failed to match, which are already reported
+var errFailed = errors.New("failed")
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintf(os.Stderr, "usage: pav match|trace|tokens [flags] [files]\nbuiltin grammars: %s\n",
+			strings.Join(grammars.Names(), " "))
+		os.Exit(2)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	err := commands[os.Args[1]](os.Args[2:], w)
+	if e := w.Flush(); err == nil {
+		err = e
+	}
+	if err == errFailed {
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "pav: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	routines map[string]pav.Routine
+	start    string
+	files    []string
+	token    string
+	skip     []string
+}
+
+func parse(name string, args []string, defaultGrammar string) (*options, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	grammar := flags.String("grammar", defaultGrammar, "builtin grammar name or text grammar file")
+	start := flags.String("start", "", "start rule, default to the start rule of the builtin grammar")
+	var token, skip *string
+	if name == "tokens" {
+		token = flags.String("token", "Token", "rule of tokens")
+		skip = flags.String("skip", "Comment,Blank", "comma separated rules of skipped text")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *grammar == "" {
+		return nil, fmt.Errorf("-grammar is required")
+	}
+	routines, defaultStart, err := grammars.Load(*grammar)
+	if err != nil {
+		return nil, err
+	}
+	if *start == "" {
+		*start = defaultStart
+	}
+	if *start == "" {
+		return nil, fmt.Errorf("-start is required")
+	}
+	if _, ok := routines[*start]; !ok {
+		return nil, fmt.Errorf("no such name: %s", *start)
+	}
+	opts := &options{
+		routines: routines,
+		start:    *start,
+		files:    flags.Args(),
+	}
+	if token != nil {
+		opts.token = *token
+		if *skip != "" {
+			opts.skip = strings.Split(*skip, ",")
+		}
+	}
+	if len(opts.files) == 0 {
+		opts.files = []string{"-"}
+	}
+	return opts, nil
+}
+
+func open(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
+
+func displayName(file string) string {
+	if file == "-" {
+		return "<stdin>"
+	}
+	return file
+}
+
+// position tracks the line and column of a rune stream
+type position struct {
+	line, col int
+}
+
+func (p *position) advance(r rune) {
+	if p.line == 0 {
+		p.line, p.col = 1, 1
+	}
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+}
+
+func (p position) String() string {
+	if p.line == 0 {
+		return "1:1"
+	}
+	return fmt.Sprintf("%d:%d", p.line, p.col)
+}
+
+// run feeds the file to a new VM, calling fn after each step. It returns a non-empty failure message if not matched.
+func run(opts *options, file string, fn func(pos position, r rune, vm *pav.VM, res pav.StepResult)) (string, error) {
+	f, err := open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	vm := pav.NewVM(opts.routines, pav.Named(opts.start))
+	var pos position
+	matched := false
+	for {
+		c, size, err := r.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if c == utf8.RuneError && size == 1 {
+			return fmt.Sprintf("%s: invalid UTF-8", pos), nil
+		}
+		res := vm.Step(c)
+		if fn != nil {
+			fn(pos, c, vm, res)
+		}
+		matched = len(res.Matched) > 0
+		if len(vm.Threads) == 0 {
+			if _, _, err := r.ReadRune(); err == io.EOF && matched {
+				return "", nil
+			}
+			if matched {
+				// failed at the next rune
+				pos.advance(c)
+			}
+			return fmt.Sprintf("%s: syntax error", pos), nil
+		}
+		pos.advance(c)
+	}
+	if !matched {
+		return fmt.Sprintf("%s: unexpected end of input", pos), nil
+	}
+	return "", nil
+}
+
+func match(args []string, w io.Writer) error {
+	opts, err := parse("match", args, "json")
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, file := range opts.files {
+		failure, err := run(opts, file, nil)
+		if err != nil {
+			return err
+		}
+		if failure != "" {
+			failed = true
+			fmt.Fprintf(w, "%s:%s\n", displayName(file), failure)
+		} else {
+			fmt.Fprintf(w, "%s: ok\n", displayName(file))
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func trace(args []string, w io.Writer) error {
+	opts, err := parse("trace", args, "json")
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, file := range opts.files {
+		failure, err := run(opts, file, func(pos position, r rune, vm *pav.VM, res pav.StepResult) {
+			fmt.Fprintf(w, "%s:%s %q threads=%d matched=%d failed=%d\n",
+				displayName(file), pos, r, len(vm.Threads), len(res.Matched), len(res.Failed))
+		})
+		if err != nil {
+			return err
+		}
+		if failure != "" {
+			failed = true
+			fmt.Fprintf(w, "%s:%s\n", displayName(file), failure)
+		} else {
+			fmt.Fprintf(w, "%s: ok\n", displayName(file))
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// tokens splits the input to the longest matches of the token rule or the skip rules, and prints the tokens
+func tokens(args []string, w io.Writer) error {
+	opts, err := parse("tokens", args, "golexer")
+	if err != nil {
+		return err
+	}
+	var rules []*pav.Instruction
+	for _, name := range append([]string{opts.token}, opts.skip...) {
+		if _, ok := opts.routines[name]; !ok {
+			return fmt.Errorf("no such name: %s", name)
+		}
+		rules = append(rules, pav.Named(name))
+	}
+	start := pav.Longest(rules...)
+
+	failed := false
+	for _, file := range opts.files {
+		f, err := open(file)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		// runes before the first invalid encoding
+		var runes []rune
+		invalid := false
+		for len(content) > 0 {
+			r, size := utf8.DecodeRune(content)
+			if r == utf8.RuneError && size == 1 {
+				invalid = true
+				break
+			}
+			runes = append(runes, r)
+			content = content[size:]
+		}
+
+		vm := pav.NewVM(opts.routines, nil)
+		vm.Capture = true
+		var pos position
+		offset := 0
+		for offset < len(runes) {
+			vm.Reset(start)
+			var last *pav.Thread
+			end := offset
+			for i := offset; i < len(runes); i++ {
+				var next rune
+				if i+1 < len(runes) {
+					next = runes[i+1]
+				}
+				res := vm.StepPeek(runes[i], next, i+1 < len(runes))
+				if len(res.Matched) > 0 {
+					last = res.Matched[0]
+					end = i + 1
+				}
+				if len(vm.Threads) == 0 {
+					break
+				}
+			}
+			if last == nil {
+				failed = true
+				fmt.Fprintf(w, "%s:%s: syntax error\n", displayName(file), pos)
+				break
+			}
+			if node := last.Tree().Find(opts.token); node != nil {
+				kind := node.Name
+				if len(node.Children) > 0 {
+					kind = node.Children[0].Name
+				}
+				fmt.Fprintf(w, "%s:%s %s %q\n",
+					displayName(file), pos, kind, string(runes[offset:end]))
+			}
+			for _, r := range runes[offset:end] {
+				pos.advance(r)
+			}
+			offset = end
+		}
+		if invalid && offset == len(runes) {
+			failed = true
+			fmt.Fprintf(w, "%s:%s: invalid UTF-8\n", displayName(file), pos)
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMatch(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"ok.json":   `{"a": [1, 2]}`,
+		"bad.json":  "{\n\"a\" 1}",
+		"eof.json":  `[1, 2`,
+		"tail.json": `1]`,
+		"utf8.json": "[\"a\xff\"]",
+		"g.pav":     "A <- 'a'+ B?\nB <- 'b'\n",
+		"ab.txt":    "aab",
+	})
+	defer os.RemoveAll(dir)
+
+	buf := new(bytes.Buffer)
+	err := match([]string{
+		filepath.Join(dir, "ok.json"),
+		filepath.Join(dir, "bad.json"),
+		filepath.Join(dir, "eof.json"),
+		filepath.Join(dir, "tail.json"),
+		filepath.Join(dir, "utf8.json"),
+	}, buf)
+	if err != errFailed {
+		t.Fatalf("got %v", err)
+	}
+	expected := dir + "/ok.json: ok\n" +
+		dir + "/bad.json:2:5: syntax error\n" +
+		dir + "/eof.json:1:6: unexpected end of input\n" +
+		dir + "/tail.json:1:2: syntax error\n" +
+		dir + "/utf8.json:1:4: invalid UTF-8\n"
+	if buf.String() != expected {
+		t.Fatalf("got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = match([]string{"-start", "Value", filepath.Join(dir, "ok.json")}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	err = match([]string{"-grammar", filepath.Join(dir, "g.pav"), "-start", "A", filepath.Join(dir, "ab.txt")}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = match([]string{"-grammar", filepath.Join(dir, "g.pav"), filepath.Join(dir, "ab.txt")}, buf)
+	if err == nil || err.Error() != "-start is required" {
+		t.Fatalf("got %v", err)
+	}
+	err = match([]string{"-start", "Foo"}, buf)
+	if err == nil || err.Error() != "no such name: Foo" {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json": `[1]`,
+	})
+	defer os.RemoveAll(dir)
+
+	buf := new(bytes.Buffer)
+	err := trace([]string{"-start", "Array", filepath.Join(dir, "a.json")}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 4 {
+		t.Fatalf("got %s", buf.String())
+	}
+	if !bytes.HasPrefix(lines[0], []byte(dir+"/a.json:1:1 '[' threads=")) {
+		t.Fatalf("got %s", lines[0])
+	}
+	if !bytes.Contains(lines[2], []byte("threads=0 matched=1 ")) {
+		t.Fatalf("got %s", lines[2])
+	}
+}
+
+func TestTokens(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.go":    "package main // foo\n\nvar variable = `b` + 1e-3\n",
+		"bad.go":  "package ?",
+		"utf8.go": "var a\xff",
+	})
+	defer os.RemoveAll(dir)
+
+	buf := new(bytes.Buffer)
+	err := tokens([]string{filepath.Join(dir, "a.go")}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := dir + `/a.go:1:1 Keyword "package"
+` + dir + `/a.go:1:9 Identifier "main"
+` + dir + `/a.go:3:1 Keyword "var"
+` + dir + `/a.go:3:5 Identifier "variable"
+` + dir + `/a.go:3:14 OperatorAndPunctuation "="
+` + dir + "/a.go:3:16 Literal \"`b`\"\n" +
+		dir + `/a.go:3:20 OperatorAndPunctuation "+"
+` + dir + `/a.go:3:22 Literal "1e-3"
+`
+	if buf.String() != expected {
+		t.Fatalf("got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = tokens([]string{filepath.Join(dir, "bad.go")}, buf)
+	if err != errFailed {
+		t.Fatalf("got %v", err)
+	}
+	if buf.String() != dir+`/bad.go:1:1 Keyword "package"
+`+dir+"/bad.go:1:9: syntax error\n" {
+		t.Fatalf("got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = tokens([]string{filepath.Join(dir, "utf8.go")}, buf)
+	if err != errFailed {
+		t.Fatalf("got %v", err)
+	}
+	if buf.String() != dir+`/utf8.go:1:1 Keyword "var"
+`+dir+`/utf8.go:1:5 Identifier "a"
+`+dir+"/utf8.go:1:6: invalid UTF-8\n" {
+		t.Fatalf("got %s", buf.String())
+	}
+}
@@ -0,0 +1,61 @@
+// Command pavgen generates Go source of a standalone matcher for a grammar.
+//
+//	pavgen -grammar json -start Text -pkg foo -type JSON -o json.go
+//
+// The grammar is one of the builtin grammar objects, or a text grammar file with .pav or .abnf extension.
+// Grammar objects defined in other packages can be generated by calling pav.Generate with pav.ObjectRoutines.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/reusee/pav"
+	"github.com/reusee/pav/internal/grammars"
+)
+
+var (
+	grammar  = flag.String("grammar", "", "builtin grammar name or text grammar file")
+	start    = flag.String("start", "", "start rule, default to the start rule of the builtin grammar")
+	pkg      = flag.String("pkg", "main", "package name")
+	typeName = flag.String("type", "Matcher", "matcher type name")
+	output   = flag.String("o", "", "output file, default stdout")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "pavgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *grammar == "" {
+		return fmt.Errorf("-grammar is required, builtin grammars: %s", strings.Join(grammars.Names(), " "))
+	}
+	routines, defaultStart, err := grammars.Load(*grammar)
+	if err != nil {
+		return err
+	}
+	if *start == "" {
+		*start = defaultStart
+	}
+	if *start == "" {
+		return fmt.Errorf("-start is required")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := pav.Generate(buf, *pkg, *typeName, routines, *start); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(*output, buf.Bytes(), 0644)
+}
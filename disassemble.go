@@ -0,0 +1,158 @@
+package pav
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Disassemble writes a listing of the instruction graph reachable from inst, including the called routines.
+// Instructions are labeled by depth-first order, L0, L1, ... for the entry, and Name+0, Name+1, ... for routines sorted by name.
+// Targets are labels, or ret for nil targets which return from the call.
+func Disassemble(w io.Writer, inst *Instruction, routines map[string]Routine) error {
+	// reachable routines
+	called := make(map[string]bool)
+	visited := make(map[*Instruction]bool)
+	var names []string
+	collect := func(i *Instruction) bool {
+		if i.Op == OpCall && i.Inst == nil && i.Name != "" && !called[i.Name] {
+			called[i.Name] = true
+			names = append(names, i.Name)
+		}
+		return true
+	}
+	walkInstructions(inst, visited, collect)
+	for i := 0; i < len(names); i++ {
+		r, ok := routines[names[i]]
+		if !ok {
+			return fmt.Errorf("no such name: %s", names[i])
+		}
+		walkInstructions(r.Start, visited, collect)
+	}
+	sort.Strings(names)
+
+	// labels
+	labels := make(map[*Instruction]string)
+	var sections [][]*Instruction
+	label := func(prefix string, start *Instruction) {
+		var section []*Instruction
+		walkInstructions(start, nil, func(i *Instruction) bool {
+			if _, ok := labels[i]; ok {
+				return false
+			}
+			labels[i] = fmt.Sprintf("%s%d", prefix, len(section))
+			section = append(section, i)
+			return true
+		})
+		sections = append(sections, section)
+	}
+	label("L", inst)
+	for _, name := range names {
+		label(name+"+", routines[name].Start)
+	}
+
+	target := func(i *Instruction) string {
+		if i == nil {
+			return "ret"
+		}
+		return labels[i]
+	}
+	b := new(strings.Builder)
+	for n, section := range sections {
+		if n > 0 {
+			b.WriteString("\n")
+			fmt.Fprintf(b, "%s:\n", names[n-1])
+		}
+		if len(section) == 0 {
+			// nil start
+			b.WriteString("\tret\n")
+		}
+		for _, i := range section {
+			fmt.Fprintf(b, "%s:\t", labels[i])
+			switch i.Op {
+			case OpRune:
+				if i.Predict {
+					fmt.Fprintf(b, "predict %s -> %s", runeClass(i), target(i.Inst))
+				} else {
+					class := runeClass(i)
+					if isPlainRune(i) {
+						class = formatRune(i.Rune)
+					}
+					fmt.Fprintf(b, "rune %s -> %s", class, target(i.Next))
+				}
+			case OpCall:
+				callee := target(i.Inst)
+				if i.Inst == nil {
+					callee = i.Name
+				} else if i.Name != "" {
+					callee += " (" + i.Name + ")"
+				}
+				fmt.Fprintf(b, "call %s -> %s", callee, target(i.Next))
+			case OpJump:
+				fmt.Fprintf(b, "jump %s", target(i.Inst))
+			case OpClone:
+				b.WriteString("clone")
+				if i.ClusterType == ClusterShortest {
+					b.WriteString(" shortest")
+				}
+				for _, branch := range i.Insts {
+					if branch == nil {
+						b.WriteString(" next")
+					} else {
+						b.WriteString(" " + labels[branch])
+					}
+				}
+				fmt.Fprintf(b, " -> %s", target(i.Next))
+			case OpIndirect:
+				fmt.Fprintf(b, "indirect %s", target(*i.InstP))
+			default:
+				b.WriteString(strings.ToLower(i.Op.String()[len("Op"):]))
+			}
+			if i.Combinator != 0 {
+				b.WriteString("\t; " + i.Combinator.String()[len("Comb"):])
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// walkInstructions calls fn on instructions reachable from inst in depth-first order.
+// Successors are not walked if fn returns false. Instructions in visited are skipped if visited is not nil.
+func walkInstructions(inst *Instruction, visited map[*Instruction]bool, fn func(*Instruction) bool) {
+	if inst == nil {
+		return
+	}
+	if visited != nil {
+		if visited[inst] {
+			return
+		}
+		visited[inst] = true
+	}
+	if !fn(inst) {
+		return
+	}
+	switch inst.Op {
+	case OpRune:
+		if inst.Predict {
+			walkInstructions(inst.Inst, visited, fn)
+		} else {
+			walkInstructions(inst.Next, visited, fn)
+		}
+	case OpCall:
+		walkInstructions(inst.Inst, visited, fn)
+		walkInstructions(inst.Next, visited, fn)
+	case OpJump:
+		walkInstructions(inst.Inst, visited, fn)
+	case OpClone:
+		for _, branch := range inst.Insts {
+			walkInstructions(branch, visited, fn)
+		}
+		walkInstructions(inst.Next, visited, fn)
+	case OpIndirect:
+		walkInstructions(*inst.InstP, visited, fn)
+	}
+}
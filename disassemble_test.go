@@ -0,0 +1,65 @@
+package pav
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestDisassemble(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := Disassemble(buf, Named("Text"), ObjectRoutines(new(JSONParser))); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "json_parser.disasm")
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("not equal to %s, run go test -update to update", golden)
+	}
+}
+
+func TestDisassembleCycle(t *testing.T) {
+	var inst *Instruction
+	inst = Seq(Rune('a'), Optional(Indirect(&inst)), Named("B"))
+	buf := new(strings.Builder)
+	if err := Disassemble(buf, inst, map[string]Routine{
+		"B": {
+			Start: RuneSet('b', 'c'),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		buf.String(), `L0:	call L1 -> L2	; Seq
+L1:	rune 'a' -> ret	; Rune
+L2:	call L3 -> L6	; Seq
+L3:	clone next L4 -> ret	; Optional
+L4:	call L5 -> ret
+L5:	indirect L0	; Indirect
+L6:	call L7 -> ret	; Seq
+L7:	call B -> ret	; Named
+
+B:
+B+0:	rune [bc] -> ret	; RuneSet
+`,
+	)
+
+	err := Disassemble(buf, Named("C"), nil)
+	eq(t,
+		err != nil, true,
+		err.Error(), "no such name: C",
+	)
+}
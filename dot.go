@@ -0,0 +1,173 @@
+package pav
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDot writes the instruction graph reachable from inst in Graphviz DOT language.
+// Routines called by name are rendered as clusters, nil targets are omitted.
+func WriteDot(w io.Writer, inst *Instruction, routines map[string]Routine) error {
+	d := &dotWriter{
+		ids:      make(map[*Instruction]int),
+		clusters: make(map[string]int),
+	}
+	d.visit(inst, "")
+	for i := 0; i < len(d.names); i++ {
+		name := d.names[i]
+		r, ok := routines[name]
+		if !ok {
+			return fmt.Errorf("no such name: %s", name)
+		}
+		d.visit(r.Start, name)
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("digraph {\n")
+	b.WriteString("\tcompound=true;\n")
+	b.WriteString("\tnode [fontname=\"monospace\"];\n")
+	for _, cluster := range append([]string{""}, d.names...) {
+		indent := "\t"
+		if cluster != "" {
+			fmt.Fprintf(b, "\tsubgraph cluster_%d {\n", d.clusters[cluster])
+			fmt.Fprintf(b, "\t\tlabel=%s;\n", dotQuote(cluster))
+			indent = "\t\t"
+		}
+		for id, node := range d.nodes {
+			if node.cluster != cluster {
+				continue
+			}
+			fmt.Fprintf(b, "%sn%d [label=%s shape=%s];\n",
+				indent, id, dotQuote(node.inst.String()), dotShapes[node.inst.Op])
+		}
+		if cluster != "" {
+			b.WriteString("\t}\n")
+		}
+	}
+	for _, e := range d.edges {
+		to := e.to
+		attrs := e.attrs
+		if e.name != "" {
+			start := routines[e.name].Start
+			if start == nil {
+				continue
+			}
+			to = start
+			attrs = fmt.Sprintf("label=%s style=dotted lhead=cluster_%d", dotQuote(e.name), d.clusters[e.name])
+		}
+		fmt.Fprintf(b, "\tn%d -> n%d [%s];\n", e.from, d.ids[to], attrs)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var dotShapes = map[Op]string{
+	OpRune:     "box",
+	OpCall:     "ellipse",
+	OpJump:     "circle",
+	OpClone:    "diamond",
+	OpReturn:   "doublecircle",
+	OpIndirect: "circle",
+}
+
+type dotNode struct {
+	inst    *Instruction
+	cluster string
+}
+
+type dotEdge struct {
+	from  int
+	to    *Instruction
+	attrs string
+	// routine name of named call
+	name string
+}
+
+type dotWriter struct {
+	ids   map[*Instruction]int
+	nodes []dotNode
+	edges []dotEdge
+	// called routine names in order
+	names    []string
+	clusters map[string]int
+}
+
+func (d *dotWriter) visit(inst *Instruction, cluster string) {
+	if inst == nil {
+		return
+	}
+	if _, ok := d.ids[inst]; ok {
+		return
+	}
+	id := len(d.nodes)
+	d.ids[inst] = id
+	d.nodes = append(d.nodes, dotNode{
+		inst:    inst,
+		cluster: cluster,
+	})
+
+	edge := func(to *Instruction, attrs string) {
+		if to == nil {
+			return
+		}
+		d.edges = append(d.edges, dotEdge{
+			from:  id,
+			to:    to,
+			attrs: attrs,
+		})
+		d.visit(to, cluster)
+	}
+
+	switch inst.Op {
+
+	case OpRune:
+		if inst.Predict {
+			edge(inst.Inst, `label="predict" style=dashed`)
+		} else {
+			edge(inst.Next, `label="next"`)
+		}
+
+	case OpCall:
+		if inst.Inst != nil {
+			edge(inst.Inst, `label="inst"`)
+		} else if inst.Name != "" {
+			if _, ok := d.clusters[inst.Name]; !ok {
+				d.clusters[inst.Name] = len(d.names)
+				d.names = append(d.names, inst.Name)
+			}
+			d.edges = append(d.edges, dotEdge{
+				from: id,
+				name: inst.Name,
+			})
+		}
+		edge(inst.Next, `label="next"`)
+
+	case OpJump:
+		edge(inst.Inst, `label="jump"`)
+
+	case OpClone:
+		label := "next"
+		for i, start := range inst.Insts {
+			if start == nil {
+				// empty branch continues at next
+				label += fmt.Sprintf(" %d", i)
+			} else {
+				edge(start, fmt.Sprintf(`label="%d"`, i))
+			}
+		}
+		edge(inst.Next, fmt.Sprintf(`label="%s"`, label))
+
+	case OpIndirect:
+		edge(*inst.InstP, `label="indirect" style=dashed`)
+
+	}
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
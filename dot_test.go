@@ -0,0 +1,68 @@
+package pav
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDot(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := WriteDot(buf, Named("A"), map[string]Routine{
+		"A": {
+			Start: Seq(
+				Optional(Rune('"')),
+				ZeroOrMore(Named("B")),
+			),
+		},
+		"B": {
+			Start: Rune('\\'),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		buf.String(), `digraph {
+	compound=true;
+	node [fontname="monospace"];
+	n0 [label="OpCall A at dot_test.go:10" shape=ellipse];
+	subgraph cluster_0 {
+		label="A";
+		n1 [label="OpCall dot_test.go:13 at dot_test.go:12" shape=ellipse];
+		n2 [label="OpClone nil dot_test.go:13 at dot_test.go:13" shape=diamond];
+		n3 [label="OpCall dot_test.go:13 at dot_test.go:13" shape=ellipse];
+		n4 [label="OpRune '\"' at dot_test.go:13" shape=box];
+		n5 [label="OpCall dot_test.go:14 at dot_test.go:12" shape=ellipse];
+		n6 [label="OpClone nil dot_test.go:14 at dot_test.go:14" shape=diamond];
+		n7 [label="OpCall dot_test.go:14 at dot_test.go:14" shape=ellipse];
+		n8 [label="OpCall B at dot_test.go:14" shape=ellipse];
+		n9 [label="OpIndirect dot_test.go:14 at dot_test.go:14" shape=circle];
+	}
+	subgraph cluster_1 {
+		label="B";
+		n10 [label="OpRune '\\\\' at dot_test.go:18" shape=box];
+	}
+	n0 -> n1 [label="A" style=dotted lhead=cluster_0];
+	n1 -> n2 [label="inst"];
+	n2 -> n3 [label="1"];
+	n3 -> n4 [label="inst"];
+	n1 -> n5 [label="next"];
+	n5 -> n6 [label="inst"];
+	n6 -> n7 [label="1"];
+	n7 -> n8 [label="inst"];
+	n8 -> n10 [label="B" style=dotted lhead=cluster_1];
+	n7 -> n9 [label="next"];
+	n9 -> n6 [label="indirect" style=dashed];
+}
+`,
+	)
+
+	err = WriteDot(buf, Seq(Named("A"), Named("C")), map[string]Routine{
+		"A": {
+			Start: Rune('a'),
+		},
+	})
+	eq(t,
+		err.Error(), "no such name: C",
+	)
+}
@@ -0,0 +1,124 @@
+package pav
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format prints the instruction as a Go expression of the constructors building it.
+// Instructions not built by constructors are printed as comments, and Indirect as Indirect(nil) since cycles are not expressible.
+func Format(inst *Instruction) string {
+	b := new(strings.Builder)
+	formatInst(b, inst, 0)
+	return b.String()
+}
+
+func formatInst(b *strings.Builder, inst *Instruction, depth int) {
+	if inst == nil {
+		b.WriteString("nil")
+		return
+	}
+
+	switch inst.Combinator {
+
+	case CombLiteral:
+		var runes []rune
+		for i := inst; i != nil; i = i.Next {
+			runes = append(runes, i.Rune)
+		}
+		b.WriteString("Literal(" + strconv.Quote(string(runes)) + ")")
+
+	case CombRuneSeq:
+		b.WriteString("RuneSeq([]rune{")
+		for i := inst; i != nil; i = i.Next {
+			if i != inst {
+				b.WriteString(", ")
+			}
+			b.WriteString(formatRune(i.Rune))
+		}
+		b.WriteString("})")
+
+	case CombRuneSet:
+		b.WriteString("RuneSet(")
+		for i, r := range inst.Runes {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(formatRune(r))
+		}
+		b.WriteString(")")
+
+	case CombRuneRange:
+		fmt.Fprintf(b, "RuneRange(%s, %s)", formatRune(inst.RuneRange[0]), formatRune(inst.RuneRange[1]))
+
+	case CombRune:
+		fmt.Fprintf(b, "Rune(%s)", formatRune(inst.Rune))
+
+	case CombAnyRune:
+		b.WriteString("AnyRune()")
+
+	case CombRuneCategory:
+		fmt.Fprintf(b, "RuneCategory(%q)", inst.Category)
+
+	case CombNamed:
+		fmt.Fprintf(b, "Named(%q)", inst.Name)
+
+	case CombIndirect:
+		b.WriteString("Indirect(nil)")
+
+	case CombKeywords, CombLongestLiteral:
+		b.WriteString(inst.Combinator.String()[len("Comb"):] + "(")
+		for i, word := range trieWords(inst, nil, nil) {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Quote(word))
+		}
+		b.WriteString(")")
+
+	case CombRuneInverse, CombSeq, CombShortest, CombFirst, CombLongest,
+		CombOptional, CombZeroOrMore, CombOneOrMore, CombRunePredict, CombRuneClass:
+		b.WriteString(inst.Combinator.String()[len("Comb"):] + "(")
+		if len(inst.Operands) == 1 && isFormatLeaf(inst.Operands[0]) {
+			formatInst(b, inst.Operands[0], depth)
+			b.WriteString(")")
+			return
+		}
+		b.WriteString("\n")
+		for _, operand := range inst.Operands {
+			b.WriteString(strings.Repeat("\t", depth+1))
+			formatInst(b, operand, depth+1)
+			b.WriteString(",\n")
+		}
+		b.WriteString(strings.Repeat("\t", depth) + ")")
+
+	default:
+		if inst.Op == OpReturn {
+			b.WriteString("&Instruction{Op: OpReturn}")
+		} else {
+			b.WriteString("/* " + inst.String() + " */ nil")
+		}
+
+	}
+}
+
+func isFormatLeaf(inst *Instruction) bool {
+	if inst == nil {
+		return true
+	}
+	switch inst.Combinator {
+	case CombRuneInverse, CombSeq, CombShortest, CombFirst, CombLongest,
+		CombOptional, CombZeroOrMore, CombOneOrMore, CombRunePredict, CombRuneClass:
+		return false
+	}
+	return true
+}
+
+func formatRune(r rune) string {
+	if utf8.ValidRune(r) {
+		return strconv.QuoteRune(r)
+	}
+	return fmt.Sprintf("0x%x", r)
+}
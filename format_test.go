@@ -0,0 +1,202 @@
+package pav
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	eq(t,
+		Format(nil), "nil",
+		Format(Literal("a\"b")), `Literal("a\"b")`,
+		Format(RuneSeq([]rune("ab"))), `RuneSeq([]rune{'a', 'b'})`,
+		Format(Optional(RuneSet('a', '\n'))), `Optional(RuneSet('a', '\n'))`,
+		Format(Seq(
+			Named("A"),
+			ZeroOrMore(RuneRange('0', '9')),
+			First(AnyRune(), RuneInverse(RuneCategory("L"))),
+		)), `Seq(
+	Named("A"),
+	ZeroOrMore(RuneRange('0', '9')),
+	First(
+		AnyRune(),
+		RuneInverse(RuneCategory("L")),
+	),
+)`,
+		Format(RunePredict(Rune('a'), emptyInstruction())), `RunePredict(
+	Rune('a'),
+	&Instruction{Op: OpReturn},
+)`,
+		Format(&Instruction{Op: OpJump}), `/* OpJump nil */ nil`,
+		Format(Keywords("a", "ab", "b")), `Keywords("a", "b")`,
+		Format(LongestLiteral("ab", "b", "a", "")), `LongestLiteral("", "a", "ab", "b")`,
+	)
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	var grammars []map[string]Routine
+	for _, obj := range []interface{}{
+		new(JSONParser),
+		new(JSON5Parser),
+		new(TextGrammarParser),
+		new(GoLexer),
+	} {
+		grammars = append(grammars, ObjectRoutines(obj))
+	}
+	abnf, err := CompileABNF(`a = 2*3"a" [ %x63-65 ] *( DIGIT / "x" )`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grammars = append(grammars, abnf)
+
+	for _, routines := range grammars {
+		rebuilt := make(map[string]Routine)
+		for name, r := range routines {
+			src := Format(r.Start)
+			inst, err := evalFormat(src)
+			if err != nil {
+				t.Fatalf("%s: %v\n%s", name, err, src)
+			}
+			if s := Format(inst); s != src {
+				t.Fatalf("%s: not round trip\n%s\n%s", name, src, s)
+			}
+			rebuilt[name] = Routine{
+				Start: inst,
+			}
+		}
+		if _, ok := rebuilt["Text"]; !ok {
+			continue
+		}
+		for _, input := range []string{
+			`{"a": [1, 2.5e3, true, null, "A"]}` + "\n",
+			`[1,]` + "\n",
+		} {
+			eq(t,
+				match(NewVM(rebuilt, Named("Text")), input),
+				match(NewVM(routines, Named("Text")), input),
+			)
+		}
+	}
+}
+
+// evalFormat builds the instruction from the output of Format
+func evalFormat(src string) (*Instruction, error) {
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	return evalInst(expr)
+}
+
+func evalInst(expr ast.Expr) (*Instruction, error) {
+	switch expr := expr.(type) {
+
+	case *ast.Ident:
+		if expr.Name == "nil" {
+			return nil, nil
+		}
+
+	case *ast.UnaryExpr:
+		// &Instruction{Op: OpReturn}
+		return emptyInstruction(), nil
+
+	case *ast.CallExpr:
+		name := expr.Fun.(*ast.Ident).Name
+		var insts []*Instruction
+		var runes []rune
+		var str string
+		var strs []string
+		for _, arg := range expr.Args {
+			switch arg := arg.(type) {
+			case *ast.BasicLit:
+				if arg.Kind == token.STRING {
+					s, err := strconv.Unquote(arg.Value)
+					if err != nil {
+						return nil, err
+					}
+					str = s
+					strs = append(strs, s)
+				} else {
+					r, err := evalRune(arg)
+					if err != nil {
+						return nil, err
+					}
+					runes = append(runes, r)
+				}
+			case *ast.CompositeLit:
+				for _, elt := range arg.Elts {
+					r, err := evalRune(elt.(*ast.BasicLit))
+					if err != nil {
+						return nil, err
+					}
+					runes = append(runes, r)
+				}
+			default:
+				inst, err := evalInst(arg)
+				if err != nil {
+					return nil, err
+				}
+				insts = append(insts, inst)
+			}
+		}
+		switch name {
+		case "Literal":
+			return Literal(str), nil
+		case "Keywords":
+			return Keywords(strs...), nil
+		case "LongestLiteral":
+			return LongestLiteral(strs...), nil
+		case "RuneSeq":
+			return RuneSeq(runes), nil
+		case "RuneSet":
+			return RuneSet(runes...), nil
+		case "RuneRange":
+			return RuneRange(runes[0], runes[1]), nil
+		case "Rune":
+			return Rune(runes[0]), nil
+		case "AnyRune":
+			return AnyRune(), nil
+		case "RuneCategory":
+			return RuneCategory(str), nil
+		case "Named":
+			return Named(str), nil
+		case "Indirect":
+			return Indirect(new(*Instruction)), nil
+		case "RuneClass":
+			return RuneClass(insts...), nil
+		case "RuneInverse":
+			return RuneInverse(insts[0]), nil
+		case "RunePredict":
+			return RunePredict(insts[0], insts[1]), nil
+		case "Seq":
+			return Seq(insts...), nil
+		case "Shortest":
+			return Shortest(insts...), nil
+		case "First":
+			return First(insts...), nil
+		case "Longest":
+			return Longest(insts...), nil
+		case "Optional":
+			return Optional(insts[0]), nil
+		case "ZeroOrMore":
+			return ZeroOrMore(insts[0]), nil
+		case "OneOrMore":
+			return OneOrMore(insts[0]), nil
+		}
+
+	}
+	return nil, fmt.Errorf("bad expression at %d", expr.Pos())
+}
+
+func evalRune(lit *ast.BasicLit) (rune, error) {
+	if lit.Kind == token.INT {
+		r, err := strconv.ParseInt(lit.Value, 0, 32)
+		return rune(r), err
+	}
+	r, _, _, err := strconv.UnquoteChar(lit.Value[1:len(lit.Value)-1], '\'')
+	return r, err
+}
@@ -0,0 +1,575 @@
+package pav
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Generate writes Go source of package pkg defining a matcher type named typeName.
+// The matcher runs the routine named start with the same semantics as VM, without reflection or pointer chasing.
+// Unexported identifiers in the source are prefixed by the lower-cased type name,
+// so multiple matchers can be generated to the same package.
+func Generate(w io.Writer, pkg string, typeName string, routines map[string]Routine, start string) error {
+	if _, ok := routines[start]; !ok {
+		return fmt.Errorf("no such name: %s", start)
+	}
+	g := &generator{
+		routines: routines,
+		indexes:  make(map[*Instruction]int),
+		clusters: make(map[*Instruction]int),
+		entries:  make(map[int]string),
+	}
+	startIndex, err := g.named(start)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(g.insts); i++ {
+		if err := g.resolve(i); err != nil {
+			return err
+		}
+	}
+
+	prefix := unexportedName(typeName)
+	buf := new(bytes.Buffer)
+	if err := generateTemplate.Execute(buf, map[string]interface{}{
+		"Package":    pkg,
+		"Type":       typeName,
+		"Prefix":     prefix,
+		"Start":      startIndex,
+		"Insts":      g.insts,
+		"Entries":    g.entries,
+		"Categories": g.categories(),
+	}); err != nil { // NOCOVER
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil { // NOCOVER
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type genInst struct {
+	inst *Instruction
+	// clone branch call
+	branch *Instruction
+
+	Op       string
+	Next     int
+	Target   int
+	Insts    []int
+	Cluster  int
+	Shortest bool
+	Predict  bool
+	Fresh    bool
+	Test     string
+}
+
+type generator struct {
+	routines map[string]Routine
+	insts    []*genInst
+	indexes  map[*Instruction]int
+	clusters map[*Instruction]int
+	entries  map[int]string
+	// used unicode categories
+	categorySet map[string]bool
+}
+
+func (g *generator) named(name string) (int, error) {
+	r, ok := g.routines[name]
+	if !ok {
+		return 0, fmt.Errorf("no such name: %s", name)
+	}
+	i := g.index(r.Start)
+	if _, ok := g.entries[i]; !ok && i >= 0 {
+		g.entries[i] = name
+	}
+	return i, nil
+}
+
+// index returns the index of the instruction in the generated table, -1 for nil
+func (g *generator) index(inst *Instruction) int {
+	if inst == nil {
+		return -1
+	}
+	if i, ok := g.indexes[inst]; ok {
+		return i
+	}
+	i := len(g.insts)
+	g.indexes[inst] = i
+	g.insts = append(g.insts, &genInst{
+		inst: inst,
+	})
+	return i
+}
+
+func (g *generator) cluster(inst *Instruction) int {
+	if inst == nil {
+		return 0
+	}
+	if n, ok := g.clusters[inst]; ok {
+		return n
+	}
+	n := len(g.clusters) + 1
+	g.clusters[inst] = n
+	return n
+}
+
+func (g *generator) resolve(i int) error {
+	gi := g.insts[i]
+
+	if gi.branch != nil {
+		// call instruction created by VM for each clone branch, not counted in bounds
+		gi.Op = "Call"
+		gi.Fresh = true
+		gi.Target = g.index(gi.branch)
+		gi.Next = g.index(gi.inst.Next)
+		if gi.inst.ClusterType != 0 {
+			gi.Cluster = g.cluster(gi.inst)
+		}
+		gi.Shortest = gi.inst.ClusterType == ClusterShortest
+		return nil
+	}
+
+	inst := gi.inst
+	gi.Next = g.index(inst.Next)
+	gi.Target = -1
+	switch inst.Op {
+
+	case OpRune:
+		gi.Op = "Rune"
+		gi.Test = g.runeTest(inst)
+		if inst.Predict {
+			gi.Predict = true
+			gi.Target = g.index(inst.Inst)
+		}
+
+	case OpCall:
+		gi.Op = "Call"
+		gi.Cluster = g.cluster(inst.Cluster)
+		gi.Shortest = inst.ClusterType == ClusterShortest
+		if inst.Inst != nil {
+			gi.Target = g.index(inst.Inst)
+		} else if inst.Name != "" {
+			target, err := g.named(inst.Name)
+			if err != nil {
+				return fmt.Errorf("%v at %s", err, inst.Pos())
+			}
+			gi.Target = target
+		} else {
+			return fmt.Errorf("bad instruction: %s", inst)
+		}
+
+	case OpJump:
+		gi.Op = "Jump"
+		gi.Target = g.index(inst.Inst)
+
+	case OpIndirect:
+		gi.Op = "Jump"
+		gi.Target = g.index(*inst.InstP)
+
+	case OpClone:
+		gi.Op = "Clone"
+		for _, start := range inst.Insts {
+			if start == nil {
+				gi.Insts = append(gi.Insts, gi.Next)
+				continue
+			}
+			gi.Insts = append(gi.Insts, len(g.insts))
+			g.insts = append(g.insts, &genInst{
+				inst:   inst,
+				branch: start,
+			})
+		}
+
+	case OpReturn:
+		gi.Op = "Return"
+
+	default:
+		return fmt.Errorf("bad instruction: %s", inst)
+	}
+
+	return nil
+}
+
+func (g *generator) runeTest(inst *Instruction) string {
+	t, f := "true", "false"
+	if inst.Inverse {
+		t, f = f, t
+	}
+	var b strings.Builder
+	if tableOnly(inst) {
+		b.WriteString("switch {\ncase ")
+		for i, r := range inst.Table.Ranges {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			if r[0] == r[1] {
+				fmt.Fprintf(&b, "r == %s", runeLiteral(r[0]))
+			} else {
+				fmt.Fprintf(&b, "r >= %s && r <= %s", runeLiteral(r[0]), runeLiteral(r[1]))
+			}
+		}
+		fmt.Fprintf(&b, ":\nreturn %s\n}\nreturn %s", t, f)
+	} else if len(inst.Runes) > 0 {
+		runes := make([]rune, len(inst.Runes))
+		copy(runes, inst.Runes)
+		sort.Slice(runes, func(i, j int) bool {
+			return runes[i] < runes[j]
+		})
+		b.WriteString("switch r {\ncase ")
+		for i, r := range runes {
+			if i > 0 && r == runes[i-1] {
+				continue
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(runeLiteral(r))
+		}
+		fmt.Fprintf(&b, ":\nreturn %s\n}\nreturn %s", t, f)
+	} else if inst.RuneRange[0] != inst.RuneRange[1] {
+		if inst.Inverse {
+			fmt.Fprintf(&b, "return r < %s || r > %s", runeLiteral(inst.RuneRange[0]), runeLiteral(inst.RuneRange[1]))
+		} else {
+			fmt.Fprintf(&b, "return r >= %s && r <= %s", runeLiteral(inst.RuneRange[0]), runeLiteral(inst.RuneRange[1]))
+		}
+	} else if inst.Category != "" {
+		if g.categorySet == nil {
+			g.categorySet = make(map[string]bool)
+		}
+		g.categorySet[inst.Category] = true
+		not := ""
+		if inst.Inverse {
+			not = "!"
+		}
+		fmt.Fprintf(&b, "return %sunicode.Is(PREFIXCategory%s, r)", not, inst.Category)
+	} else {
+		op := "=="
+		if inst.Inverse {
+			op = "!="
+		}
+		fmt.Fprintf(&b, "return r %s %s", op, runeLiteral(inst.Rune))
+	}
+	return b.String()
+}
+
+func (g *generator) categories() []string {
+	var ret []string
+	for category := range g.categorySet {
+		ret = append(ret, category)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// unexportedName lower-cases the leading initialism or letter, like JSONDecoder to jsonDecoder
+func unexportedName(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+	if n == 0 {
+		n = 1
+	}
+	return strings.ToLower(string(runes[:n])) + string(runes[n:])
+}
+
+func runeLiteral(r rune) string {
+	if utf8.ValidRune(r) && r != utf8.RuneError && (r < utf8.RuneSelf || unicode.IsPrint(r)) {
+		return fmt.Sprintf("%q", r)
+	}
+	return fmt.Sprintf("0x%x", r)
+}
+
+var generateTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"replace": strings.Replace,
+}).Parse(`// Code generated by pavgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if .Categories }}
+	"unicode"
+{{- end }}
+	"unicode/utf8"
+)
+
+// {{ .Type }} is a lockstep matcher
+type {{ .Type }} struct {
+	threads []*{{ .Prefix }}Thread
+}
+
+type {{ .Prefix }}Thread struct {
+	stack []{{ .Prefix }}Frame
+	pc    int
+	match bool
+	stats []{{ .Prefix }}Stat
+}
+
+type {{ .Prefix }}Frame struct {
+	ret      int
+	cluster  int
+	shortest bool
+}
+
+type {{ .Prefix }}Stat struct {
+	pc      int
+	counter int
+}
+
+type {{ .Prefix }}Instruction struct {
+	op       uint8
+	next     int
+	target   int
+	insts    []int
+	cluster  int
+	shortest bool
+	predict  bool
+	fresh    bool
+}
+
+const (
+	{{ .Prefix }}OpRune = iota + 1
+	{{ .Prefix }}OpCall
+	{{ .Prefix }}OpJump
+	{{ .Prefix }}OpClone
+	{{ .Prefix }}OpReturn
+)
+
+const {{ .Prefix }}Start = {{ .Start }}
+
+const {{ .Prefix }}Bound = 64
+
+{{ if .Categories }}
+var (
+{{- range .Categories }}
+	{{ $.Prefix }}Category{{ . }} = unicode.Categories[{{ printf "%q" . }}]
+{{- end }}
+)
+{{ end }}
+
+var {{ .Prefix }}Insts = [...]{{ .Prefix }}Instruction{
+{{- range $i, $inst := .Insts }}
+	{{ with index $.Entries $i }}// {{ . }}
+	{{ end -}}
+	{ {{- $.Prefix }}Op{{ .Op }}, {{ .Next }}, {{ .Target }}, {{ if .Insts }}[]int{ {{- range $j, $e := .Insts }}{{ if $j }}, {{ end }}{{ $e }}{{ end -}} }{{ else }}nil{{ end }}, {{ .Cluster }}, {{ .Shortest }}, {{ .Predict }}, {{ .Fresh }}}, // {{ $i }}
+{{- end }}
+}
+
+func {{ .Prefix }}MatchRune(pc int, r rune) bool {
+	switch pc {
+{{- range $i, $inst := .Insts }}{{ if eq .Op "Rune" }}
+	case {{ $i }}:
+		{{ replace .Test "PREFIX" $.Prefix -1 }}
+{{- end }}{{ end }}
+	}
+	panic("bad instruction")
+}
+
+func New{{ .Type }}() *{{ .Type }} {
+	m := new({{ .Type }})
+	m.Reset()
+	return m
+}
+
+// Reset restarts matching from the start rule
+func (m *{{ .Type }}) Reset() {
+	m.threads = append(m.threads[:0], &{{ .Prefix }}Thread{
+		pc: {{ .Prefix }}Start,
+	})
+}
+
+// Alive reports whether there are threads expecting more input
+func (m *{{ .Type }}) Alive() bool {
+	return len(m.threads) > 0
+}
+
+// Match reports whether the whole input matches the start rule
+func (m *{{ .Type }}) Match(input string) bool {
+	m.Reset()
+	matched := false
+	for len(input) > 0 {
+		r, size := utf8.DecodeRuneInString(input)
+		input = input[size:]
+		matched = m.Step(r)
+		if len(m.threads) == 0 {
+			return matched && len(input) == 0
+		}
+	}
+	return matched
+}
+
+// Step feeds one rune and reports whether any thread matched the input so far
+func (m *{{ .Type }}) Step(r rune) bool {
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		thread := m.threads[i]
+		if thread.pc >= 0 && {{ .Prefix }}Insts[thread.pc].op != {{ .Prefix }}OpRune {
+			// added by predicting instructions
+			m.prepare(thread)
+		}
+	feed:
+		if thread.pc >= 0 {
+			thread.match = {{ .Prefix }}MatchRune(thread.pc, r)
+			if thread.match {
+				inst := &{{ .Prefix }}Insts[thread.pc]
+				if inst.predict {
+					thread.pc = inst.target
+					m.prepare(thread)
+					goto feed
+				}
+				thread.pc = inst.next
+			} else {
+				m.kill(thread)
+			}
+		}
+		thread.stats = thread.stats[:0]
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	matched := false
+	threads := m.threads[:0]
+	for _, thread := range m.threads {
+		if thread.pc < 0 {
+			if thread.match {
+				matched = true
+			}
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	for i := len(threads); i < len(m.threads); i++ {
+		m.threads[i] = nil
+	}
+	m.threads = threads
+
+	return matched
+}
+
+func (m *{{ .Type }}) prepare(thread *{{ .Prefix }}Thread) {
+	for {
+
+		if thread.pc < 0 {
+			// implicit return
+			if len(thread.stack) == 0 {
+				return
+			}
+			m.unwind(thread)
+			continue
+		}
+
+		inst := &{{ .Prefix }}Insts[thread.pc]
+		if inst.op == {{ .Prefix }}OpRune {
+			return
+		}
+
+		if !inst.fresh {
+			added := false
+			for i, stat := range thread.stats {
+				if stat.pc == thread.pc {
+					if stat.counter >= {{ .Prefix }}Bound {
+						m.kill(thread)
+						return
+					}
+					thread.stats[i].counter++
+					added = true
+					break
+				}
+			}
+			if !added {
+				thread.stats = append(thread.stats, {{ .Prefix }}Stat{
+					pc: thread.pc,
+				})
+			}
+		}
+
+		switch inst.op {
+
+		case {{ .Prefix }}OpCall:
+			if inst.next >= 0 || inst.cluster > 0 {
+				thread.stack = append(thread.stack, {{ .Prefix }}Frame{
+					ret:      inst.next,
+					cluster:  inst.cluster,
+					shortest: inst.shortest,
+				})
+			}
+			thread.pc = inst.target
+
+		case {{ .Prefix }}OpJump:
+			thread.pc = inst.target
+
+		case {{ .Prefix }}OpClone:
+			for i, pc := range inst.insts {
+				t := thread
+				if i > 0 {
+					t = &{{ .Prefix }}Thread{
+						stack: append([]{{ .Prefix }}Frame(nil), thread.stack...),
+						stats: append([]{{ .Prefix }}Stat(nil), thread.stats...),
+						match: thread.match,
+					}
+					m.threads = append(m.threads, t)
+				}
+				t.pc = pc
+			}
+
+		case {{ .Prefix }}OpReturn:
+			if len(thread.stack) == 0 {
+				thread.pc = -1
+				return
+			}
+			m.unwind(thread)
+
+		}
+
+	}
+}
+
+func (m *{{ .Type }}) unwind(thread *{{ .Prefix }}Thread) {
+	frame := thread.stack[len(thread.stack)-1]
+	thread.pc = frame.ret
+	thread.stack = thread.stack[:len(thread.stack)-1]
+	if frame.shortest && thread.match {
+		// kill threads in the same cluster
+	loop:
+		for _, t := range m.threads {
+			if t == thread {
+				continue
+			}
+			for _, f := range t.stack {
+				if f.cluster == frame.cluster {
+					m.kill(t)
+					continue loop
+				}
+			}
+		}
+	}
+}
+
+func (m *{{ .Type }}) kill(thread *{{ .Prefix }}Thread) {
+	for len(thread.stack) > 0 {
+		m.unwind(thread)
+	}
+	thread.pc = -1
+	thread.match = false
+}
+`))
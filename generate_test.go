@@ -0,0 +1,64 @@
+package pav
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := Generate(buf, "foo", "Matcher", map[string]Routine{
+		"A": {
+			Start: Seq(
+				RuneCategory("Lu"),
+				RuneInverse(RuneRange('a', 'z')),
+				RuneInverse(RuneClass(RuneRange('0', '9'), Rune('_'))),
+				First(Named("A"), Rune('.')),
+			),
+		},
+	}, "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, s := range []string{
+		"package foo\n",
+		"type Matcher struct",
+		"func NewMatcher() *Matcher",
+		`matcherCategoryLu = unicode.Categories["Lu"]`,
+		"return unicode.Is(matcherCategoryLu, r)",
+		"return r < 'a' || r > 'z'",
+		"return r == '.'",
+		"case r >= '0' && r <= '9',\n",
+		"r == '_':\n\t\t\treturn false",
+	} {
+		if !strings.Contains(src, s) {
+			t.Fatalf("expecting %q", s)
+		}
+	}
+
+	err = Generate(buf, "foo", "Matcher", map[string]Routine{
+		"A": {
+			Start: Named("B"),
+		},
+	}, "A")
+	eq(t,
+		err.Error(), "no such name: B at generate_test.go:43",
+	)
+	err = Generate(buf, "foo", "Matcher", nil, "A")
+	eq(t,
+		err.Error(), "no such name: A",
+	)
+}
+
+func TestUnexportedName(t *testing.T) {
+	eq(t,
+		unexportedName("Matcher"), "matcher",
+		unexportedName("JSON"), "json",
+		unexportedName("JSON5"), "json5",
+		unexportedName("JSONMatcher"), "jsonMatcher",
+		unexportedName("X"), "x",
+		unexportedName("matcher"), "matcher",
+	)
+}
@@ -23,8 +23,9 @@ func (_ GoLexer) Comment() *Instruction {
 	)
 }
 
+// Token matches the longest keyword, identifier, operator or literal
 func (_ GoLexer) Token() *Instruction {
-	return First(
+	return Longest(
 		Named("Keyword"),
 		Named("Identifier"),
 		Named("OperatorAndPunctuation"),
@@ -64,86 +65,84 @@ func (_ GoLexer) Letter() *Instruction {
 }
 
 func (_ GoLexer) Keyword() *Instruction {
-	return First(
-		Literal("break"),
-		Literal("case"),
-		Literal("chan"),
-		Literal("const"),
-		Literal("continue"),
-		Literal("default"),
-		Literal("defer"),
-		Literal("else"),
-		Literal("fallthrough"),
-		Literal("for"),
-		Literal("func"),
-		Longest(
-			Literal("go"),
-			Literal("goto"),
-		),
-		Literal("if"),
-		Literal("import"),
-		Literal("interface"),
-		Literal("map"),
-		Literal("package"),
-		Literal("range"),
-		Literal("return"),
-		Literal("select"),
-		Literal("struct"),
-		Literal("switch"),
-		Literal("type"),
-		Literal("var"),
+	return LongestLiteral(
+		"break",
+		"case",
+		"chan",
+		"const",
+		"continue",
+		"default",
+		"defer",
+		"else",
+		"fallthrough",
+		"for",
+		"func",
+		"go",
+		"goto",
+		"if",
+		"import",
+		"interface",
+		"map",
+		"package",
+		"range",
+		"return",
+		"select",
+		"struct",
+		"switch",
+		"type",
+		"var",
 	)
 }
 
 func (_ GoLexer) OperatorAndPunctuation() *Instruction {
-	return Longest(
-		Literal("+"),
-		Literal("&"),
-		Literal("+="),
-		Literal("&="),
-		Literal("&&"),
-		Literal("=="),
-		Literal("!="),
-		Literal("("),
-		Literal(")"),
-		Literal("-"),
-		Literal("|"),
-		Literal("-="),
-		Literal("|="),
-		Literal("||"),
-		Literal("<"),
-		Literal("<="),
-		Literal("["),
-		Literal("]"),
-		Literal("*"),
-		Literal("^"),
-		Literal("*="),
-		Literal("^="),
-		Literal("<-"),
-		Literal(">"),
-		Literal(">="),
-		Literal("{"),
-		Literal("}"),
-		Literal("/"),
-		Literal("<<"),
-		Literal("/="),
-		Literal("<<="),
-		Literal("++"),
-		Literal("="),
-		Literal(":="),
-		Literal(","),
-		Literal(";"),
-		Literal("%"),
-		Literal(">>"),
-		Literal("%="),
-		Literal(">>="),
-		Literal("--"),
-		Literal("!"),
-		Literal("..."),
-		Literal("."),
-		Literal(":"),
-		Literal("&^"),
-		Literal("&^="),
+	return LongestLiteral(
+		"+",
+		"&",
+		"+=",
+		"&=",
+		"&&",
+		"==",
+		"!=",
+		"(",
+		")",
+		"-",
+		"|",
+		"-=",
+		"|=",
+		"||",
+		"<",
+		"<=",
+		"[",
+		"]",
+		"*",
+		"^",
+		"*=",
+		"^=",
+		"<-",
+		">",
+		">=",
+		"{",
+		"}",
+		"/",
+		"<<",
+		"/=",
+		"<<=",
+		"++",
+		"=",
+		":=",
+		",",
+		";",
+		"%",
+		">>",
+		"%=",
+		">>=",
+		"--",
+		"!",
+		"...",
+		".",
+		":",
+		"&^",
+		"&^=",
 	)
 }
 
@@ -203,6 +202,16 @@ func (_ GoLexer) Decimals() *Instruction {
 	)
 }
 
+func (_ GoLexer) Exponent() *Instruction {
+	return Seq(
+		RuneSet('e', 'E'),
+		Optional(
+			RuneSet('+', '-'),
+		),
+		Named("Decimals"),
+	)
+}
+
 func (_ GoLexer) ImaginaryLiteral() *Instruction {
 	return Seq(
 		Longest(
@@ -305,6 +314,10 @@ func (_ GoLexer) GeneralComment() *Instruction {
 	)
 }
 
+func (_ GoLexer) UnicodeChar() *Instruction {
+	return RuneInverse(Rune('\n'))
+}
+
 func (_ GoLexer) UnicodeValue() *Instruction {
 	return Longest(
 		RuneInverse(Rune('\n')),
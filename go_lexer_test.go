@@ -50,3 +50,48 @@ func TestGoLexer(t *testing.T) {
 		return nil
 	})
 }
+
+func TestGoLexerToken(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		kind  string
+	}{
+		// keywords are also identifiers, First would commit to Keyword and reject identifiers
+		{"if", "Keyword"},
+		{"foo", "Identifier"},
+		{"format", "Identifier"},
+		// longer operators share prefixes with shorter ones
+		{"=", "OperatorAndPunctuation"},
+		{"==", "OperatorAndPunctuation"},
+		{"&^=", "OperatorAndPunctuation"},
+		// exponents and raw strings refer to Exponent and UnicodeChar
+		{"1.5", "Literal"},
+		{"1e3", "Literal"},
+		{"1.5e-3", "Literal"},
+		{"`b`", "Literal"},
+		{"`a\nb`", "Literal"},
+		{"'a'", "Literal"},
+		{`"x"`, "Literal"},
+	} {
+		vm := NewVMFromObject(new(GoLexer), Named("Token"))
+		vm.Capture = true
+		var res StepResult
+		for _, r := range c.input {
+			res = vm.Step(r)
+		}
+		if len(res.Matched) == 0 {
+			t.Fatalf("not matched: %q", c.input)
+		}
+		node := res.Matched[0].Tree().Find("Token")
+		eq(t,
+			node != nil, true,
+			node.Children[0].Name, c.kind,
+		)
+	}
+
+	for _, f := range Analyze(ObjectRoutines(new(GoLexer))).Findings {
+		if f.Kind == UndefinedName {
+			t.Fatalf("undefined name: %s", f.Name)
+		}
+	}
+}
@@ -1,166 +1,421 @@
 package pav
 
 import (
-	"sync/atomic"
+	"runtime"
+	"sort"
+	"sync"
 	"unicode"
+	"unicode/utf8"
 )
 
-var nextClusterID int64
+// RecordPositions enables recording the caller position of instruction constructors, shown by Instruction.Pos.
+// Disable it before constructing grammars to speed up the construction.
+// It is read by every constructor without synchronization: set it before creating instructions,
+// typically in an init function or at the start of main, and never change it while other goroutines construct instructions.
+var RecordPositions = true
+
+var instructionFile = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return file
+}()
+
+type callerPos struct {
+	file string
+	line int
+}
+
+// program counter -> *callerPos, nil if all frames of the counter are in this file
+var callerPosCache sync.Map
+
+// at records the position of the first caller outside this file
+func at(inst *Instruction) *Instruction {
+	if !RecordPositions {
+		return inst
+	}
+	var pcs [32]uintptr
+	skip := 2
+	for {
+		n := runtime.Callers(skip, pcs[:])
+		for _, pc := range pcs[:n] {
+			v, ok := callerPosCache.Load(pc)
+			if !ok {
+				var pos *callerPos
+				frames := runtime.CallersFrames([]uintptr{pc})
+				for {
+					frame, more := frames.Next()
+					if frame.File != instructionFile {
+						pos = &callerPos{
+							file: frame.File,
+							line: frame.Line,
+						}
+						break
+					}
+					if !more {
+						break
+					}
+				}
+				v, _ = callerPosCache.LoadOrStore(pc, pos)
+			}
+			if pos := v.(*callerPos); pos != nil {
+				inst.file = pos.file
+				inst.line = pos.line
+				return inst
+			}
+		}
+		if n < len(pcs) { // NOCOVER
+			return inst
+		}
+		skip += n
+	}
+}
 
 func Literal(s string) *Instruction {
-	return RuneSeq([]rune(s))
+	inst := RuneSeq([]rune(s))
+	if inst != nil {
+		inst.Combinator = CombLiteral
+	}
+	return inst
+}
+
+// Keywords matches one of the words, the shortest one if more than one match, as First of Literals.
+// Words are compiled to a trie, so words with common prefixes share threads.
+func Keywords(words ...string) *Instruction {
+	inst := literalTrie(words, false)
+	if inst != nil {
+		inst.Combinator = CombKeywords
+	}
+	return inst
+}
+
+// LongestLiteral matches one of the words, the longest one if more than one match, as Longest of Literals.
+// Words are compiled to a trie, so words with common prefixes share threads.
+func LongestLiteral(words ...string) *Instruction {
+	inst := literalTrie(words, true)
+	if inst != nil {
+		inst.Combinator = CombLongestLiteral
+	}
+	return inst
+}
+
+func literalTrie(words []string, longest bool) *Instruction {
+	// group by the first rune
+	var firsts []rune
+	suffixes := make(map[rune][]string)
+	end := false
+	for _, word := range words {
+		if word == "" {
+			end = true
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word)
+		if _, ok := suffixes[r]; !ok {
+			firsts = append(firsts, r)
+		}
+		suffixes[r] = append(suffixes[r], word[size:])
+	}
+	if end && !longest || len(firsts) == 0 {
+		// matched
+		return nil
+	}
+
+	var branches []*Instruction
+	if end {
+		// return or match more
+		branches = append(branches, nil)
+	}
+	for _, r := range firsts {
+		branches = append(branches, at(&Instruction{
+			Op:   OpRune,
+			Rune: r,
+			Next: literalTrie(suffixes[r], longest),
+		}))
+	}
+	if len(branches) == 1 {
+		return branches[0]
+	}
+	// at most one branch accepts the next rune, no cluster needed
+	return at(&Instruction{
+		Op:    OpClone,
+		Insts: branches,
+	})
+}
+
+// trieWords returns the words matched by the trie compiled by literalTrie
+func trieWords(inst *Instruction, prefix []rune, words []string) []string {
+	if inst == nil {
+		return append(words, string(prefix))
+	}
+	switch inst.Op {
+	case OpRune:
+		return trieWords(inst.Next, append(prefix, inst.Rune), words)
+	case OpClone:
+		for _, branch := range inst.Insts {
+			words = trieWords(branch, prefix[:len(prefix):len(prefix)], words)
+		}
+	}
+	return words
 }
 
 func RuneSeq(runes []rune) *Instruction {
 	if len(runes) == 0 {
 		return nil
 	}
-	return &Instruction{
-		Op:   OpRune,
-		Rune: runes[0],
-		Next: RuneSeq(runes[1:]),
-	}
+	return at(&Instruction{
+		Op:         OpRune,
+		Rune:       runes[0],
+		Next:       RuneSeq(runes[1:]),
+		Combinator: CombRuneSeq,
+	})
 }
 
 func RuneSet(runes ...rune) *Instruction {
-	return &Instruction{
-		Op:    OpRune,
-		Runes: runes,
+	ranges := make([][2]rune, 0, len(runes))
+	for _, r := range runes {
+		ranges = append(ranges, [2]rune{r, r})
 	}
+	return at(&Instruction{
+		Op:         OpRune,
+		Runes:      runes,
+		Table:      NewRuneTable(ranges),
+		Combinator: CombRuneSet,
+	})
 }
 
-func RuneRange(r1, r2 rune) *Instruction {
-	return &Instruction{
-		Op:        OpRune,
-		RuneRange: [2]rune{r1, r2},
+// RuneClass matches one rune matched by any of the rune instructions, like RuneSet, RuneRange, RuneCategory and RuneInverse of them
+func RuneClass(classes ...*Instruction) *Instruction {
+	var ranges [][2]rune
+	for _, class := range classes {
+		ranges = append(ranges, runeTableOf(class).Ranges...)
 	}
+	return at(&Instruction{
+		Op:         OpRune,
+		Table:      NewRuneTable(ranges),
+		Combinator: CombRuneClass,
+		Operands:   classes,
+	})
+}
+
+func RuneRange(r1, r2 rune) *Instruction {
+	return at(&Instruction{
+		Op:         OpRune,
+		RuneRange:  [2]rune{r1, r2},
+		Combinator: CombRuneRange,
+	})
 }
 
 func Rune(r rune) *Instruction {
-	return &Instruction{
-		Op:   OpRune,
-		Rune: r,
-	}
+	return at(&Instruction{
+		Op:         OpRune,
+		Rune:       r,
+		Combinator: CombRune,
+	})
 }
 
 func AnyRune() *Instruction {
-	return &Instruction{
-		Op:        OpRune,
-		RuneRange: [2]rune{0, unicode.MaxRune},
-	}
+	return at(&Instruction{
+		Op:         OpRune,
+		RuneRange:  [2]rune{0, unicode.MaxRune},
+		Combinator: CombAnyRune,
+	})
 }
 
 func RuneInverse(inst *Instruction) *Instruction {
 	i := *inst
 	i.Inverse = true
-	return &i
+	i.Combinator = CombRuneInverse
+	i.Operands = []*Instruction{inst}
+	return at(&i)
 }
 
 func Seq(instructions ...*Instruction) *Instruction {
 	if len(instructions) == 0 {
 		return nil
 	}
-	return &Instruction{
-		Op:   OpCall,
-		Inst: instructions[0],
-		Next: Seq(instructions[1:]...),
-	}
+	return at(&Instruction{
+		Op:         OpCall,
+		Inst:       instructions[0],
+		Next:       Seq(instructions[1:]...),
+		Combinator: CombSeq,
+		Operands:   instructions,
+	})
 }
 
 func Shortest(instructions ...*Instruction) *Instruction {
 	if len(instructions) == 0 { // NOCOVER
 		return nil
 	}
-	return &Instruction{
+	return at(&Instruction{
 		Op:          OpClone,
 		Insts:       instructions,
-		ClusterID:   atomic.AddInt64(&nextClusterID, 1),
 		ClusterType: ClusterShortest,
-	}
+		Combinator:  CombShortest,
+		Operands:    instructions,
+	})
 }
 
 func First(instructions ...*Instruction) *Instruction {
-	return Shortest(instructions...)
+	inst := Shortest(instructions...)
+	if inst != nil {
+		inst.Combinator = CombFirst
+	}
+	return inst
 }
 
 func Longest(instructions ...*Instruction) *Instruction {
 	if len(instructions) == 0 { // NOCOVER
 		return nil
 	}
-	return &Instruction{
-		Op:    OpClone,
-		Insts: instructions,
-	}
+	return at(&Instruction{
+		Op:         OpClone,
+		Insts:      instructions,
+		Combinator: CombLongest,
+		Operands:   instructions,
+	})
 }
 
 func Optional(inst *Instruction) *Instruction {
-	return &Instruction{
+	return at(&Instruction{
 		Op: OpClone,
 		Insts: []*Instruction{
 			// zero
 			nil,
 			// one
-			{
+			at(&Instruction{
 				Op:   OpCall,
 				Inst: inst,
-			},
+			}),
 		},
-	}
+		Combinator: CombOptional,
+		Operands:   []*Instruction{inst},
+	})
 }
 
 func ZeroOrMore(inst *Instruction) *Instruction {
 	var cloneInst *Instruction
-	cloneInst = &Instruction{
+	cloneInst = at(&Instruction{
 		Op: OpClone,
 		Insts: []*Instruction{
 			// zero
 			nil,
 			// more
-			&Instruction{
+			at(&Instruction{
 				Op:   OpCall,
 				Inst: inst,
-				Next: &Instruction{
+				Next: at(&Instruction{
 					Op:    OpIndirect,
 					InstP: &cloneInst,
-				},
-			},
+				}),
+			}),
 		},
-	}
+		Combinator: CombZeroOrMore,
+		Operands:   []*Instruction{inst},
+	})
 	return cloneInst
 }
 
 func OneOrMore(inst *Instruction) *Instruction {
-	return Seq(
+	ret := Seq(
 		// one
 		inst,
 		// more
 		ZeroOrMore(inst),
 	)
+	ret.Combinator = CombOneOrMore
+	ret.Operands = []*Instruction{inst}
+	return ret
 }
 
 func Indirect(p **Instruction) *Instruction {
-	return &Instruction{
-		Op:    OpIndirect,
-		InstP: p,
-	}
+	return at(&Instruction{
+		Op:         OpIndirect,
+		InstP:      p,
+		Combinator: CombIndirect,
+	})
 }
 
 func Named(name string) *Instruction {
-	return &Instruction{
-		Op:   OpCall,
-		Name: name,
-	}
+	return at(&Instruction{
+		Op:         OpCall,
+		Name:       name,
+		Combinator: CombNamed,
+	})
 }
 
 func RuneCategory(category string) *Instruction {
-	return &Instruction{
-		Op:       OpRune,
-		Category: category,
-	}
+	return at(&Instruction{
+		Op:         OpRune,
+		Category:   category,
+		Table:      categoryTable(category),
+		Combinator: CombRuneCategory,
+	})
 }
 
 func RunePredict(predict *Instruction, cont *Instruction) *Instruction {
-	predict.Predict = true
-	predict.Inst = cont
-	return predict
+	i := *predict
+	i.Predict = true
+	i.Inst = cont
+	i.Combinator = CombRunePredict
+	i.Operands = []*Instruction{predict, cont}
+	return at(&i)
+}
+
+// emptyInstruction matches empty string
+func emptyInstruction() *Instruction {
+	return at(&Instruction{
+		Op: OpReturn,
+	})
+}
+
+// runeRanges returns an instruction matching one rune in the inclusive ranges
+func runeRanges(ranges [][2]rune, inverse bool) *Instruction {
+	var classes []*Instruction
+	for _, r := range normalizeRanges(ranges) {
+		if r[0] == r[1] {
+			classes = append(classes, Rune(r[0]))
+		} else {
+			classes = append(classes, RuneRange(r[0], r[1]))
+		}
+	}
+	inst := RuneClass(classes...)
+	if inverse {
+		inst = RuneInverse(inst)
+	}
+	return inst
+}
+
+func normalizeRanges(ranges [][2]rune) [][2]rune {
+	sorted := make([][2]rune, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0]
+	})
+	var ret [][2]rune
+	for _, r := range sorted {
+		if r[0] > r[1] {
+			continue
+		}
+		if n := len(ret); n > 0 && r[0] <= ret[n-1][1]+1 {
+			if r[1] > ret[n-1][1] {
+				ret[n-1][1] = r[1]
+			}
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+func complementRanges(ranges [][2]rune) [][2]rune {
+	var ret [][2]rune
+	next := rune(0)
+	for _, r := range ranges {
+		if r[0] > next {
+			ret = append(ret, [2]rune{next, r[0] - 1})
+		}
+		next = r[1] + 1
+	}
+	if next <= unicode.MaxRune {
+		ret = append(ret, [2]rune{next, unicode.MaxRune})
+	}
+	return ret
 }
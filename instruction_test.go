@@ -1,6 +1,9 @@
 package pav
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestRuneSeq(t *testing.T) {
 	runes := []rune("abcdefg")
@@ -538,3 +541,93 @@ func TestFirst(t *testing.T) {
 		}
 	}
 }
+
+func TestPos(t *testing.T) {
+	lit := Literal("ab")
+	seq := Seq(
+		Rune('a'),
+		ZeroOrMore(
+			RuneInverse(Rune('b')),
+		),
+	)
+	eq(t,
+		lit.Pos(), "instruction_test.go:546",
+		lit.Next.Pos(), "instruction_test.go:546",
+		seq.Pos(), "instruction_test.go:547",
+		seq.Inst.Pos(), "instruction_test.go:548",
+		seq.Next.Inst.Pos(), "instruction_test.go:549",
+		seq.Next.Inst.Insts[1].Next.Pos(), "instruction_test.go:549",
+		seq.Next.Inst.Insts[1].Inst.Pos(), "instruction_test.go:550",
+		seq.Inst.String(), "OpRune 'a' at instruction_test.go:548",
+		(*Instruction)(nil).Pos(), "nil",
+	)
+
+	RecordPositions = false
+	defer func() {
+		RecordPositions = true
+	}()
+	inst := Rune('a')
+	eq(t,
+		inst.Pos(), fmt.Sprintf("%p", inst),
+		inst.String(), "OpRune 'a'",
+	)
+}
+
+func TestPosPanic(t *testing.T) {
+	vm := NewVM(nil, Named("foo"))
+	func() {
+		defer func() {
+			eq(t,
+				fmt.Sprint(recover()), "no such name: foo at instruction_test.go:577",
+			)
+		}()
+		vm.Step('a')
+	}()
+}
+
+func TestKeywords(t *testing.T) {
+	words := []string{"in", "int", "if", "interface", "i", "go", "goto", "<", "<<", "<<="}
+	var literals []*Instruction
+	for _, word := range words {
+		literals = append(literals, Literal(word))
+	}
+	for _, input := range []string{
+		"i", "in", "int", "inter", "interface", "interfaces", "if", "go", "got", "goto", "<", "<<", "<<=", "<=", "x", "",
+	} {
+		var matched [4][]int
+		for i, inst := range []*Instruction{
+			First(literals...),
+			Keywords(words...),
+			Longest(literals...),
+			LongestLiteral(words...),
+		} {
+			vm := NewVM(nil, inst)
+			for j, r := range input {
+				if len(vm.Step(r).Matched) > 0 {
+					matched[i] = append(matched[i], j)
+				}
+			}
+		}
+		eq(t,
+			fmt.Sprintf("%v", matched[1]), fmt.Sprintf("%v", matched[0]),
+			fmt.Sprintf("%v", matched[3]), fmt.Sprintf("%v", matched[2]),
+		)
+	}
+
+	// threads
+	vm := NewVM(nil, LongestLiteral(words...))
+	vm.Step('i')
+	eq(t,
+		len(vm.Threads), 2,
+	)
+	vm = NewVM(nil, Keywords(words...))
+	vm.Step('i')
+	eq(t,
+		len(vm.Threads), 0,
+	)
+
+	eq(t,
+		Keywords() == nil, true,
+		LongestLiteral("") == nil, true,
+	)
+}
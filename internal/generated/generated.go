@@ -0,0 +1,6 @@
+// Package generated contains matchers generated by pavgen, for testing the generator
+package generated
+
+//go:generate go run ../../cmd/pavgen -grammar json -start Text -pkg generated -type JSON -o json.go
+//go:generate go run ../../cmd/pavgen -grammar json5 -start Text -pkg generated -type JSON5 -o json5.go
+//go:generate go run ../../cmd/pavgen -grammar text -start Grammar -pkg generated -type TextGrammar -o text_grammar.go
@@ -0,0 +1,113 @@
+package generated
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reusee/pav"
+)
+
+type matcher interface {
+	Reset()
+	Step(rune) bool
+	Alive() bool
+	Match(string) bool
+}
+
+var matchers = []struct {
+	typeName string
+	file     string
+	object   interface{}
+	start    string
+	new      func() matcher
+}{
+	{"JSON", "json.go", new(pav.JSONParser), "Text", func() matcher { return NewJSON() }},
+	{"JSON5", "json5.go", new(pav.JSON5Parser), "Text", func() matcher { return NewJSON5() }},
+	{"TextGrammar", "text_grammar.go", new(pav.TextGrammarParser), "Grammar", func() matcher { return NewTextGrammar() }},
+}
+
+func TestUpToDate(t *testing.T) {
+	for _, m := range matchers {
+		buf := new(bytes.Buffer)
+		if err := pav.Generate(buf, "generated", m.typeName, pav.ObjectRoutines(m.object), m.start); err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadFile(m.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), content) {
+			t.Fatalf("%s is out of date, run go generate", m.file)
+		}
+	}
+}
+
+func TestEquivalence(t *testing.T) {
+	inputs := []string{
+		``,
+		`{"a": [1, 2.5e-3, true, false, null, "\u00e9\n"]}`,
+		`{a: 'b', c: [0x1F, .5, +Infinity, NaN,], /* c */ ünïcödé: 1} // x`,
+		`[1, 2,`,
+		"Value <- Blank (String / Number | Object)*  # comment\nBlank <- [ \\t\\n]+ / [^a-z\\-] .\n",
+		"A <- 'a' B?\nB <- \"\\u0041\" / A+\n",
+		"A <- ",
+	}
+	files, err := filepath.Glob(filepath.Join("..", "..", "testdata", "json", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(content))
+	}
+
+	for _, m := range matchers {
+		for _, input := range inputs {
+			vm := pav.NewVMFromObject(m.object, pav.Named(m.start))
+			gen := m.new()
+			vmMatched := false
+			for i, r := range []rune(input) {
+				vmMatched = len(vm.Step(r).Matched) > 0
+				if gen.Step(r) != vmMatched || gen.Alive() != (len(vm.Threads) > 0) {
+					t.Fatalf("%s: differ at rune %d of %q", m.typeName, i, input)
+				}
+			}
+			if gen.Match(input) != vmMatched {
+				t.Fatalf("%s: differ on %q", m.typeName, input)
+			}
+		}
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	input := "[" + strings.Repeat(`{"foo": [1, 2.5, "bar"]}, `, 1024) + "null]"
+	b.SetBytes(int64(len(input)))
+
+	b.Run("vm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			vm := pav.NewVMFromObject(new(pav.JSONParser), pav.Named("Text"))
+			var res pav.StepResult
+			for _, r := range input {
+				res = vm.Step(r)
+			}
+			if len(res.Matched) == 0 {
+				b.Fatal("should match")
+			}
+		}
+	})
+
+	b.Run("generated", func(b *testing.B) {
+		m := NewJSON()
+		for i := 0; i < b.N; i++ {
+			if !m.Match(input) {
+				b.Fatal("should match")
+			}
+		}
+	})
+}
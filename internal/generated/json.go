@@ -0,0 +1,623 @@
+// Code generated by pavgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"unicode/utf8"
+)
+
+// JSON is a lockstep matcher
+type JSON struct {
+	threads []*jsonThread
+}
+
+type jsonThread struct {
+	stack []jsonFrame
+	pc    int
+	match bool
+	stats []jsonStat
+}
+
+type jsonFrame struct {
+	ret      int
+	cluster  int
+	shortest bool
+}
+
+type jsonStat struct {
+	pc      int
+	counter int
+}
+
+type jsonInstruction struct {
+	op       uint8
+	next     int
+	target   int
+	insts    []int
+	cluster  int
+	shortest bool
+	predict  bool
+	fresh    bool
+}
+
+const (
+	jsonOpRune = iota + 1
+	jsonOpCall
+	jsonOpJump
+	jsonOpClone
+	jsonOpReturn
+)
+
+const jsonStart = 0
+
+const jsonBound = 64
+
+var jsonInsts = [...]jsonInstruction{
+	// Text
+	{jsonOpCall, 1, 2, nil, 0, false, false, false},  // 0
+	{jsonOpCall, -1, 3, nil, 0, false, false, false}, // 1
+	{jsonOpCall, -1, 4, nil, 0, false, false, false}, // 2
+	{jsonOpCall, -1, 5, nil, 0, false, false, false}, // 3
+	// Value
+	{jsonOpClone, -1, -1, []int{6, 7, 8, 9, 10, 11, 12}, 0, false, false, false}, // 4
+	// Blank
+	{jsonOpClone, -1, -1, []int{-1, 13}, 0, false, false, false}, // 5
+	{jsonOpCall, -1, 14, nil, 0, false, false, true},             // 6
+	{jsonOpCall, -1, 15, nil, 0, false, false, true},             // 7
+	{jsonOpCall, -1, 16, nil, 0, false, false, true},             // 8
+	{jsonOpCall, -1, 17, nil, 0, false, false, true},             // 9
+	{jsonOpCall, -1, 18, nil, 0, false, false, true},             // 10
+	{jsonOpCall, -1, 19, nil, 0, false, false, true},             // 11
+	{jsonOpCall, -1, 20, nil, 0, false, false, true},             // 12
+	{jsonOpCall, -1, 21, nil, 0, false, false, true},             // 13
+	{jsonOpCall, -1, 22, nil, 0, false, false, false},            // 14
+	{jsonOpCall, 23, 24, nil, 0, false, false, false},            // 15
+	{jsonOpCall, -1, 25, nil, 0, false, false, false},            // 16
+	{jsonOpCall, -1, 26, nil, 0, false, false, false},            // 17
+	{jsonOpCall, 27, 28, nil, 0, false, false, false},            // 18
+	{jsonOpCall, 29, 30, nil, 0, false, false, false},            // 19
+	{jsonOpCall, 31, 32, nil, 0, false, false, false},            // 20
+	{jsonOpCall, 33, 34, nil, 0, false, false, false},            // 21
+	// String
+	{jsonOpCall, 35, 36, nil, 0, false, false, false}, // 22
+	{jsonOpCall, -1, 37, nil, 0, false, false, false}, // 23
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},  // 24
+	// Object
+	{jsonOpCall, 38, 39, nil, 0, false, false, false}, // 25
+	// Array
+	{jsonOpCall, 40, 41, nil, 0, false, false, false},            // 26
+	{jsonOpCall, -1, 42, nil, 0, false, false, false},            // 27
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},             // 28
+	{jsonOpCall, -1, 43, nil, 0, false, false, false},            // 29
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},             // 30
+	{jsonOpCall, -1, 44, nil, 0, false, false, false},            // 31
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},             // 32
+	{jsonOpJump, -1, 5, nil, 0, false, false, false},             // 33
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},            // 34
+	{jsonOpCall, 45, 46, nil, 0, false, false, false},            // 35
+	{jsonOpCall, 47, 48, nil, 0, false, false, false},            // 36
+	{jsonOpCall, -1, 49, nil, 0, false, false, false},            // 37
+	{jsonOpCall, 50, 51, nil, 0, false, false, false},            // 38
+	{jsonOpCall, 52, 53, nil, 0, false, false, false},            // 39
+	{jsonOpCall, 54, 55, nil, 0, false, false, false},            // 40
+	{jsonOpCall, 56, 57, nil, 0, false, false, false},            // 41
+	{jsonOpRune, 58, -1, nil, 0, false, false, false},            // 42
+	{jsonOpRune, 59, -1, nil, 0, false, false, false},            // 43
+	{jsonOpRune, 60, -1, nil, 0, false, false, false},            // 44
+	{jsonOpCall, -1, 61, nil, 0, false, false, false},            // 45
+	{jsonOpClone, -1, -1, []int{-1, 62}, 0, false, false, false}, // 46
+	{jsonOpCall, -1, 63, nil, 0, false, false, false},            // 47
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},             // 48
+	// Number
+	{jsonOpCall, 64, 65, nil, 0, false, false, false},                                                                // 49
+	{jsonOpCall, -1, 66, nil, 0, false, false, false},                                                                // 50
+	{jsonOpClone, -1, -1, []int{-1, 67}, 0, false, false, false},                                                     // 51
+	{jsonOpCall, -1, 68, nil, 0, false, false, false},                                                                // 52
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                                                                 // 53
+	{jsonOpCall, -1, 69, nil, 0, false, false, false},                                                                // 54
+	{jsonOpClone, -1, -1, []int{-1, 70}, 0, false, false, false},                                                     // 55
+	{jsonOpCall, -1, 71, nil, 0, false, false, false},                                                                // 56
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                                                                 // 57
+	{jsonOpRune, 72, -1, nil, 0, false, false, false},                                                                // 58
+	{jsonOpRune, 73, -1, nil, 0, false, false, false},                                                                // 59
+	{jsonOpRune, 74, -1, nil, 0, false, false, false},                                                                // 60
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 61
+	{jsonOpCall, -1, 75, nil, 0, false, false, true},                                                                 // 62
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 63
+	{jsonOpCall, 76, 77, nil, 0, false, false, false},                                                                // 64
+	{jsonOpClone, -1, -1, []int{-1, 78}, 0, false, false, false},                                                     // 65
+	{jsonOpCall, 79, 80, nil, 0, false, false, false},                                                                // 66
+	{jsonOpCall, -1, 81, nil, 0, false, false, true},                                                                 // 67
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 68
+	{jsonOpCall, 82, 83, nil, 0, false, false, false},                                                                // 69
+	{jsonOpCall, -1, 84, nil, 0, false, false, true},                                                                 // 70
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 71
+	{jsonOpRune, 85, -1, nil, 0, false, false, false},                                                                // 72
+	{jsonOpRune, 86, -1, nil, 0, false, false, false},                                                                // 73
+	{jsonOpRune, 87, -1, nil, 0, false, false, false},                                                                // 74
+	{jsonOpCall, 88, 89, nil, 0, false, false, false},                                                                // 75
+	{jsonOpCall, 90, 91, nil, 0, false, false, false},                                                                // 76
+	{jsonOpClone, -1, -1, []int{92, 93}, 0, false, false, false},                                                     // 77
+	{jsonOpCall, -1, 94, nil, 0, false, false, true},                                                                 // 78
+	{jsonOpCall, -1, 95, nil, 0, false, false, false},                                                                // 79
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                                                                 // 80
+	{jsonOpCall, -1, 96, nil, 0, false, false, false},                                                                // 81
+	{jsonOpCall, -1, 97, nil, 0, false, false, false},                                                                // 82
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                                                                 // 83
+	{jsonOpCall, -1, 98, nil, 0, false, false, false},                                                                // 84
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 85
+	{jsonOpRune, 99, -1, nil, 0, false, false, false},                                                                // 86
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 87
+	{jsonOpJump, -1, 46, nil, 0, false, false, false},                                                                // 88
+	{jsonOpClone, -1, -1, []int{100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 111}, 0, false, false, false}, // 89
+	{jsonOpCall, -1, 112, nil, 0, false, false, false},                                                               // 90
+	{jsonOpClone, -1, -1, []int{-1, 113}, 0, false, false, false},                                                    // 91
+	{jsonOpCall, -1, 114, nil, 0, false, false, true},                                                                // 92
+	{jsonOpCall, -1, 115, nil, 0, false, false, true},                                                                // 93
+	{jsonOpCall, -1, 116, nil, 0, false, false, false},                                                               // 94
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 95
+	{jsonOpCall, 117, 118, nil, 0, false, false, false},                                                              // 96
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 97
+	{jsonOpCall, 119, 120, nil, 0, false, false, false},                                                              // 98
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 99
+	{jsonOpCall, -1, 121, nil, 0, false, false, true},                                                                // 100
+	{jsonOpCall, -1, 122, nil, 0, false, false, true},                                                                // 101
+	{jsonOpCall, -1, 123, nil, 0, false, false, true},                                                                // 102
+	{jsonOpCall, -1, 124, nil, 0, false, false, true},                                                                // 103
+	{jsonOpCall, -1, 125, nil, 0, false, false, true},                                                                // 104
+	{jsonOpCall, -1, 126, nil, 0, false, false, true},                                                                // 105
+	{jsonOpCall, -1, 127, nil, 0, false, false, true},                                                                // 106
+	{jsonOpCall, -1, 128, nil, 0, false, false, true},                                                                // 107
+	{jsonOpCall, -1, 129, nil, 0, false, false, true},                                                                // 108
+	{jsonOpCall, -1, 130, nil, 0, false, false, true},                                                                // 109
+	{jsonOpCall, -1, 131, nil, 0, false, false, true},                                                                // 110
+	{jsonOpCall, -1, 132, nil, 0, false, false, true},                                                                // 111
+	{jsonOpClone, -1, -1, []int{-1, 133}, 0, false, false, false},                                                    // 112
+	{jsonOpCall, -1, 134, nil, 0, false, false, true},                                                                // 113
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 114
+	{jsonOpCall, 135, 136, nil, 0, false, false, false},                                                              // 115
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 116
+	{jsonOpCall, 137, 138, nil, 0, false, false, false},                                                              // 117
+	{jsonOpCall, -1, 22, nil, 0, false, false, false},                                                                // 118
+	{jsonOpCall, -1, 139, nil, 0, false, false, false},                                                               // 119
+	{jsonOpCall, -1, 4, nil, 0, false, false, false},                                                                 // 120
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 121
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 122
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 123
+	{jsonOpRune, 140, -1, nil, 0, false, false, false},                                                               // 124
+	{jsonOpRune, 141, -1, nil, 0, false, false, false},                                                               // 125
+	{jsonOpRune, 142, -1, nil, 0, false, false, false},                                                               // 126
+	{jsonOpRune, 143, -1, nil, 0, false, false, false},                                                               // 127
+	{jsonOpRune, 144, -1, nil, 0, false, false, false},                                                               // 128
+	{jsonOpRune, 145, -1, nil, 0, false, false, false},                                                               // 129
+	{jsonOpRune, 146, -1, nil, 0, false, false, false},                                                               // 130
+	{jsonOpRune, 147, -1, nil, 0, false, false, false},                                                               // 131
+	{jsonOpCall, 148, 149, nil, 0, false, false, false},                                                              // 132
+	{jsonOpCall, -1, 150, nil, 0, false, false, true},                                                                // 133
+	{jsonOpCall, -1, 151, nil, 0, false, false, false},                                                               // 134
+	{jsonOpCall, -1, 152, nil, 0, false, false, false},                                                               // 135
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 136
+	{jsonOpCall, 153, 154, nil, 0, false, false, false},                                                              // 137
+	{jsonOpCall, 155, 156, nil, 0, false, false, false},                                                              // 138
+	{jsonOpClone, -1, -1, []int{-1, 157}, 0, false, false, false},                                                    // 139
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 140
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 141
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 142
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 143
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 144
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 145
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 146
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 147
+	{jsonOpCall, 158, 159, nil, 0, false, false, false},                                                              // 148
+	{jsonOpRune, 160, -1, nil, 0, false, false, false},                                                               // 149
+	{jsonOpCall, -1, 161, nil, 0, false, false, false},                                                               // 150
+	{jsonOpCall, 162, 163, nil, 0, false, false, false},                                                              // 151
+	{jsonOpClone, -1, -1, []int{-1, 164}, 0, false, false, false},                                                    // 152
+	{jsonOpCall, -1, 165, nil, 0, false, false, false},                                                               // 153
+	{jsonOpCall, -1, 4, nil, 0, false, false, false},                                                                 // 154
+	{jsonOpCall, -1, 166, nil, 0, false, false, false},                                                               // 155
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                                                                 // 156
+	{jsonOpCall, -1, 167, nil, 0, false, false, true},                                                                // 157
+	{jsonOpCall, 168, 169, nil, 0, false, false, false},                                                              // 158
+	{jsonOpCall, -1, 170, nil, 0, false, false, false},                                                               // 159
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 160
+	{jsonOpCall, 171, 172, nil, 0, false, false, false},                                                              // 161
+	{jsonOpCall, -1, 173, nil, 0, false, false, false},                                                               // 162
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 163
+	{jsonOpCall, -1, 174, nil, 0, false, false, true},                                                                // 164
+	{jsonOpClone, -1, -1, []int{-1, 175}, 0, false, false, false},                                                    // 165
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                                                                // 166
+	{jsonOpCall, 176, 177, nil, 0, false, false, false},                                                              // 167
+	{jsonOpCall, 178, 179, nil, 0, false, false, false},                                                              // 168
+	{jsonOpCall, -1, 170, nil, 0, false, false, false},                                                               // 169
+	// HexDigit
+	{jsonOpClone, -1, -1, []int{180, 181, 182}, 0, false, false, false}, // 170
+	{jsonOpCall, 183, 184, nil, 0, false, false, false},                 // 171
+	{jsonOpClone, -1, -1, []int{185, 186}, 0, false, false, false},      // 172
+	{jsonOpCall, 187, 188, nil, 0, false, false, false},                 // 173
+	{jsonOpCall, 189, 190, nil, 0, false, false, false},                 // 174
+	{jsonOpCall, -1, 191, nil, 0, false, false, true},                   // 175
+	{jsonOpJump, -1, 139, nil, 0, false, false, false},                  // 176
+	{jsonOpCall, 192, 193, nil, 0, false, false, false},                 // 177
+	{jsonOpCall, -1, 194, nil, 0, false, false, false},                  // 178
+	{jsonOpCall, -1, 170, nil, 0, false, false, false},                  // 179
+	{jsonOpCall, -1, 195, nil, 1, true, false, true},                    // 180
+	{jsonOpCall, -1, 196, nil, 1, true, false, true},                    // 181
+	{jsonOpCall, -1, 197, nil, 1, true, false, true},                    // 182
+	{jsonOpCall, -1, 198, nil, 0, false, false, false},                  // 183
+	{jsonOpClone, -1, -1, []int{-1, 199}, 0, false, false, false},       // 184
+	{jsonOpCall, -1, 200, nil, 0, false, false, true},                   // 185
+	{jsonOpCall, -1, 201, nil, 0, false, false, true},                   // 186
+	{jsonOpCall, -1, 202, nil, 0, false, false, false},                  // 187
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 188
+	{jsonOpJump, -1, 152, nil, 0, false, false, false},                  // 189
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 190
+	{jsonOpCall, 203, 204, nil, 0, false, false, false},                 // 191
+	{jsonOpCall, -1, 205, nil, 0, false, false, false},                  // 192
+	{jsonOpCall, 206, 207, nil, 0, false, false, false},                 // 193
+	{jsonOpCall, -1, 170, nil, 0, false, false, false},                  // 194
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 195
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 196
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 197
+	{jsonOpCall, 208, 209, nil, 0, false, false, false},                 // 198
+	{jsonOpCall, -1, 210, nil, 0, false, false, true},                   // 199
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 200
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 201
+	{jsonOpClone, -1, -1, []int{-1, 211}, 0, false, false, false},       // 202
+	{jsonOpJump, -1, 165, nil, 0, false, false, false},                  // 203
+	{jsonOpCall, 212, 213, nil, 0, false, false, false},                 // 204
+	{jsonOpCall, -1, 4, nil, 0, false, false, false},                    // 205
+	{jsonOpCall, -1, 214, nil, 0, false, false, false},                  // 206
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                    // 207
+	{jsonOpCall, -1, 215, nil, 0, false, false, false},                  // 208
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 209
+	{jsonOpCall, -1, 216, nil, 0, false, false, false},                  // 210
+	{jsonOpCall, -1, 217, nil, 0, false, false, true},                   // 211
+	{jsonOpCall, 218, 219, nil, 0, false, false, false},                 // 212
+	{jsonOpCall, 220, 221, nil, 0, false, false, false},                 // 213
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 214
+	{jsonOpClone, -1, -1, []int{-1, 222}, 0, false, false, false},       // 215
+	{jsonOpClone, -1, -1, []int{223, 224}, 0, false, false, false},      // 216
+	{jsonOpCall, 225, 188, nil, 0, false, false, false},                 // 217
+	{jsonOpCall, 226, 227, nil, 0, false, false, false},                 // 218
+	{jsonOpCall, -1, 22, nil, 0, false, false, false},                   // 219
+	{jsonOpCall, -1, 228, nil, 0, false, false, false},                  // 220
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                    // 221
+	{jsonOpCall, -1, 229, nil, 0, false, false, true},                   // 222
+	{jsonOpCall, -1, 230, nil, 0, false, false, true},                   // 223
+	{jsonOpCall, -1, 231, nil, 0, false, false, true},                   // 224
+	{jsonOpJump, -1, 202, nil, 0, false, false, false},                  // 225
+	{jsonOpCall, -1, 232, nil, 0, false, false, false},                  // 226
+	{jsonOpCall, 233, 234, nil, 0, false, false, false},                 // 227
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 228
+	{jsonOpCall, 235, 209, nil, 0, false, false, false},                 // 229
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 230
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 231
+	{jsonOpCall, -1, 4, nil, 0, false, false, false},                    // 232
+	{jsonOpCall, -1, 236, nil, 0, false, false, false},                  // 233
+	{jsonOpCall, -1, 5, nil, 0, false, false, false},                    // 234
+	{jsonOpJump, -1, 215, nil, 0, false, false, false},                  // 235
+	{jsonOpRune, -1, -1, nil, 0, false, false, false},                   // 236
+}
+
+func jsonMatchRune(pc int, r rune) bool {
+	switch pc {
+	case 34:
+		switch r {
+		case '\t', '\n', '\r', ' ':
+			return true
+		}
+		return false
+	case 42:
+		return r == 't'
+	case 43:
+		return r == 'f'
+	case 44:
+		return r == 'n'
+	case 58:
+		return r == 'r'
+	case 59:
+		return r == 'a'
+	case 60:
+		return r == 'u'
+	case 61:
+		return r == '"'
+	case 63:
+		return r == '"'
+	case 68:
+		return r == '{'
+	case 71:
+		return r == '['
+	case 72:
+		return r == 'u'
+	case 73:
+		return r == 'l'
+	case 74:
+		return r == 'l'
+	case 85:
+		return r == 'e'
+	case 86:
+		return r == 's'
+	case 87:
+		return r == 'l'
+	case 95:
+		return r == '}'
+	case 97:
+		return r == ']'
+	case 99:
+		return r == 'e'
+	case 114:
+		return r == '0'
+	case 116:
+		return r == '-'
+	case 121:
+		return r >= ' ' && r <= '!'
+	case 122:
+		return r >= '#' && r <= '['
+	case 123:
+		return r >= ']' && r <= 0x10ffff
+	case 124:
+		return r == '\\'
+	case 125:
+		return r == '\\'
+	case 126:
+		return r == '\\'
+	case 127:
+		return r == '\\'
+	case 128:
+		return r == '\\'
+	case 129:
+		return r == '\\'
+	case 130:
+		return r == '\\'
+	case 131:
+		return r == '\\'
+	case 136:
+		return r >= '1' && r <= '9'
+	case 140:
+		return r == '"'
+	case 141:
+		return r == '\\'
+	case 142:
+		return r == '/'
+	case 143:
+		return r == 'b'
+	case 144:
+		return r == 'f'
+	case 145:
+		return r == 'n'
+	case 146:
+		return r == 'r'
+	case 147:
+		return r == 't'
+	case 149:
+		return r == '\\'
+	case 160:
+		return r == 'u'
+	case 163:
+		return r == '.'
+	case 166:
+		return r == ':'
+	case 188:
+		return r >= '0' && r <= '9'
+	case 190:
+		return r >= '0' && r <= '9'
+	case 195:
+		return r >= '0' && r <= '9'
+	case 196:
+		return r >= 'a' && r <= 'f'
+	case 197:
+		return r >= 'A' && r <= 'F'
+	case 200:
+		return r == 'e'
+	case 201:
+		return r == 'E'
+	case 209:
+		return r >= '0' && r <= '9'
+	case 214:
+		return r == ','
+	case 228:
+		return r == ','
+	case 230:
+		return r == '+'
+	case 231:
+		return r == '-'
+	case 236:
+		return r == ':'
+	}
+	panic("bad instruction")
+}
+
+func NewJSON() *JSON {
+	m := new(JSON)
+	m.Reset()
+	return m
+}
+
+// Reset restarts matching from the start rule
+func (m *JSON) Reset() {
+	m.threads = append(m.threads[:0], &jsonThread{
+		pc: jsonStart,
+	})
+}
+
+// Alive reports whether there are threads expecting more input
+func (m *JSON) Alive() bool {
+	return len(m.threads) > 0
+}
+
+// Match reports whether the whole input matches the start rule
+func (m *JSON) Match(input string) bool {
+	m.Reset()
+	matched := false
+	for len(input) > 0 {
+		r, size := utf8.DecodeRuneInString(input)
+		input = input[size:]
+		matched = m.Step(r)
+		if len(m.threads) == 0 {
+			return matched && len(input) == 0
+		}
+	}
+	return matched
+}
+
+// Step feeds one rune and reports whether any thread matched the input so far
+func (m *JSON) Step(r rune) bool {
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		thread := m.threads[i]
+		if thread.pc >= 0 && jsonInsts[thread.pc].op != jsonOpRune {
+			// added by predicting instructions
+			m.prepare(thread)
+		}
+	feed:
+		if thread.pc >= 0 {
+			thread.match = jsonMatchRune(thread.pc, r)
+			if thread.match {
+				inst := &jsonInsts[thread.pc]
+				if inst.predict {
+					thread.pc = inst.target
+					m.prepare(thread)
+					goto feed
+				}
+				thread.pc = inst.next
+			} else {
+				m.kill(thread)
+			}
+		}
+		thread.stats = thread.stats[:0]
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	matched := false
+	threads := m.threads[:0]
+	for _, thread := range m.threads {
+		if thread.pc < 0 {
+			if thread.match {
+				matched = true
+			}
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	for i := len(threads); i < len(m.threads); i++ {
+		m.threads[i] = nil
+	}
+	m.threads = threads
+
+	return matched
+}
+
+func (m *JSON) prepare(thread *jsonThread) {
+	for {
+
+		if thread.pc < 0 {
+			// implicit return
+			if len(thread.stack) == 0 {
+				return
+			}
+			m.unwind(thread)
+			continue
+		}
+
+		inst := &jsonInsts[thread.pc]
+		if inst.op == jsonOpRune {
+			return
+		}
+
+		if !inst.fresh {
+			added := false
+			for i, stat := range thread.stats {
+				if stat.pc == thread.pc {
+					if stat.counter >= jsonBound {
+						m.kill(thread)
+						return
+					}
+					thread.stats[i].counter++
+					added = true
+					break
+				}
+			}
+			if !added {
+				thread.stats = append(thread.stats, jsonStat{
+					pc: thread.pc,
+				})
+			}
+		}
+
+		switch inst.op {
+
+		case jsonOpCall:
+			if inst.next >= 0 || inst.cluster > 0 {
+				thread.stack = append(thread.stack, jsonFrame{
+					ret:      inst.next,
+					cluster:  inst.cluster,
+					shortest: inst.shortest,
+				})
+			}
+			thread.pc = inst.target
+
+		case jsonOpJump:
+			thread.pc = inst.target
+
+		case jsonOpClone:
+			for i, pc := range inst.insts {
+				t := thread
+				if i > 0 {
+					t = &jsonThread{
+						stack: append([]jsonFrame(nil), thread.stack...),
+						stats: append([]jsonStat(nil), thread.stats...),
+						match: thread.match,
+					}
+					m.threads = append(m.threads, t)
+				}
+				t.pc = pc
+			}
+
+		case jsonOpReturn:
+			if len(thread.stack) == 0 {
+				thread.pc = -1
+				return
+			}
+			m.unwind(thread)
+
+		}
+
+	}
+}
+
+func (m *JSON) unwind(thread *jsonThread) {
+	frame := thread.stack[len(thread.stack)-1]
+	thread.pc = frame.ret
+	thread.stack = thread.stack[:len(thread.stack)-1]
+	if frame.shortest && thread.match {
+		// kill threads in the same cluster
+	loop:
+		for _, t := range m.threads {
+			if t == thread {
+				continue
+			}
+			for _, f := range t.stack {
+				if f.cluster == frame.cluster {
+					m.kill(t)
+					continue loop
+				}
+			}
+		}
+	}
+}
+
+func (m *JSON) kill(thread *jsonThread) {
+	for len(thread.stack) > 0 {
+		m.unwind(thread)
+	}
+	thread.pc = -1
+	thread.match = false
+}
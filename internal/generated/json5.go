@@ -0,0 +1,919 @@
+// Code generated by pavgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSON5 is a lockstep matcher
+type JSON5 struct {
+	threads []*json5Thread
+}
+
+type json5Thread struct {
+	stack []json5Frame
+	pc    int
+	match bool
+	stats []json5Stat
+}
+
+type json5Frame struct {
+	ret      int
+	cluster  int
+	shortest bool
+}
+
+type json5Stat struct {
+	pc      int
+	counter int
+}
+
+type json5Instruction struct {
+	op       uint8
+	next     int
+	target   int
+	insts    []int
+	cluster  int
+	shortest bool
+	predict  bool
+	fresh    bool
+}
+
+const (
+	json5OpRune = iota + 1
+	json5OpCall
+	json5OpJump
+	json5OpClone
+	json5OpReturn
+)
+
+const json5Start = 0
+
+const json5Bound = 64
+
+var (
+	json5CategoryL  = unicode.Categories["L"]
+	json5CategoryMc = unicode.Categories["Mc"]
+	json5CategoryMn = unicode.Categories["Mn"]
+	json5CategoryNd = unicode.Categories["Nd"]
+	json5CategoryNl = unicode.Categories["Nl"]
+)
+
+var json5Insts = [...]json5Instruction{
+	// Text
+	{json5OpCall, 1, 2, nil, 0, false, false, false},  // 0
+	{json5OpCall, 3, 4, nil, 0, false, false, false},  // 1
+	{json5OpCall, -1, 5, nil, 0, false, false, false}, // 2
+	{json5OpCall, -1, 6, nil, 0, false, false, false}, // 3
+	{json5OpCall, -1, 7, nil, 0, false, false, false}, // 4
+	// Value
+	{json5OpClone, -1, -1, []int{8, 9, 10, 11, 12, 13, 14}, 0, false, false, false}, // 5
+	{json5OpClone, -1, -1, []int{-1, 15}, 0, false, false, false},                   // 6
+	// Blank
+	{json5OpClone, -1, -1, []int{-1, 16}, 0, false, false, false}, // 7
+	{json5OpCall, -1, 17, nil, 0, false, false, true},             // 8
+	{json5OpCall, -1, 18, nil, 0, false, false, true},             // 9
+	{json5OpCall, -1, 19, nil, 0, false, false, true},             // 10
+	{json5OpCall, -1, 20, nil, 0, false, false, true},             // 11
+	{json5OpCall, -1, 21, nil, 0, false, false, true},             // 12
+	{json5OpCall, -1, 22, nil, 0, false, false, true},             // 13
+	{json5OpCall, -1, 23, nil, 0, false, false, true},             // 14
+	{json5OpCall, -1, 24, nil, 0, false, false, true},             // 15
+	{json5OpCall, -1, 25, nil, 0, false, false, true},             // 16
+	{json5OpCall, -1, 26, nil, 0, false, false, false},            // 17
+	{json5OpCall, 27, 28, nil, 0, false, false, false},            // 18
+	{json5OpCall, -1, 29, nil, 0, false, false, false},            // 19
+	{json5OpCall, -1, 30, nil, 0, false, false, false},            // 20
+	{json5OpCall, 31, 32, nil, 0, false, false, false},            // 21
+	{json5OpCall, 33, 34, nil, 0, false, false, false},            // 22
+	{json5OpCall, 35, 36, nil, 0, false, false, false},            // 23
+	{json5OpCall, -1, 37, nil, 0, false, false, false},            // 24
+	{json5OpCall, 38, 39, nil, 0, false, false, false},            // 25
+	// String
+	{json5OpClone, -1, -1, []int{40, 41}, 0, false, false, false}, // 26
+	{json5OpCall, -1, 42, nil, 0, false, false, false},            // 27
+	{json5OpCall, -1, 7, nil, 0, false, false, false},             // 28
+	// Object
+	{json5OpCall, 43, 44, nil, 0, false, false, false}, // 29
+	// Array
+	{json5OpCall, 45, 46, nil, 0, false, false, false},                        // 30
+	{json5OpCall, -1, 47, nil, 0, false, false, false},                        // 31
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 32
+	{json5OpCall, -1, 48, nil, 0, false, false, false},                        // 33
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 34
+	{json5OpCall, -1, 49, nil, 0, false, false, false},                        // 35
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 36
+	{json5OpCall, 50, 51, nil, 0, false, false, false},                        // 37
+	{json5OpJump, -1, 7, nil, 0, false, false, false},                         // 38
+	{json5OpClone, -1, -1, []int{52, 53, 54, 55, 56}, 0, false, false, false}, // 39
+	{json5OpCall, -1, 57, nil, 0, false, false, true},                         // 40
+	{json5OpCall, -1, 58, nil, 0, false, false, true},                         // 41
+	{json5OpCall, -1, 59, nil, 0, false, false, false},                        // 42
+	{json5OpCall, 60, 61, nil, 0, false, false, false},                        // 43
+	{json5OpCall, 62, 63, nil, 0, false, false, false},                        // 44
+	{json5OpCall, 64, 65, nil, 0, false, false, false},                        // 45
+	{json5OpCall, 66, 67, nil, 0, false, false, false},                        // 46
+	{json5OpRune, 68, -1, nil, 0, false, false, false},                        // 47
+	{json5OpRune, 69, -1, nil, 0, false, false, false},                        // 48
+	{json5OpRune, 70, -1, nil, 0, false, false, false},                        // 49
+	{json5OpCall, -1, 71, nil, 0, false, false, false},                        // 50
+	{json5OpRune, 72, -1, nil, 0, false, false, false},                        // 51
+	{json5OpCall, -1, 73, nil, 0, false, false, true},                         // 52
+	{json5OpCall, -1, 74, nil, 0, false, false, true},                         // 53
+	{json5OpCall, -1, 75, nil, 0, false, false, true},                         // 54
+	{json5OpCall, -1, 76, nil, 0, false, false, true},                         // 55
+	{json5OpCall, -1, 77, nil, 0, false, false, true},                         // 56
+	{json5OpCall, 78, 79, nil, 0, false, false, false},                        // 57
+	{json5OpCall, 80, 81, nil, 0, false, false, false},                        // 58
+	// Number
+	{json5OpCall, 82, 83, nil, 0, false, false, false},             // 59
+	{json5OpCall, -1, 84, nil, 0, false, false, false},             // 60
+	{json5OpClone, -1, -1, []int{-1, 85}, 0, false, false, false},  // 61
+	{json5OpCall, -1, 86, nil, 0, false, false, false},             // 62
+	{json5OpCall, -1, 7, nil, 0, false, false, false},              // 63
+	{json5OpCall, -1, 87, nil, 0, false, false, false},             // 64
+	{json5OpClone, -1, -1, []int{-1, 88}, 0, false, false, false},  // 65
+	{json5OpCall, -1, 89, nil, 0, false, false, false},             // 66
+	{json5OpCall, -1, 7, nil, 0, false, false, false},              // 67
+	{json5OpRune, 90, -1, nil, 0, false, false, false},             // 68
+	{json5OpRune, 91, -1, nil, 0, false, false, false},             // 69
+	{json5OpRune, 92, -1, nil, 0, false, false, false},             // 70
+	{json5OpClone, -1, -1, []int{-1, 93}, 0, false, false, false},  // 71
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 72
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 73
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 74
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 75
+	{json5OpCall, -1, 94, nil, 0, false, false, false},             // 76
+	{json5OpCall, -1, 95, nil, 0, false, false, false},             // 77
+	{json5OpCall, 96, 97, nil, 0, false, false, false},             // 78
+	{json5OpCall, 98, 99, nil, 0, false, false, false},             // 79
+	{json5OpCall, 100, 101, nil, 0, false, false, false},           // 80
+	{json5OpCall, 102, 103, nil, 0, false, false, false},           // 81
+	{json5OpCall, -1, 104, nil, 0, false, false, false},            // 82
+	{json5OpClone, -1, -1, []int{-1, 105}, 0, false, false, false}, // 83
+	{json5OpCall, 106, 107, nil, 0, false, false, false},           // 84
+	{json5OpCall, -1, 108, nil, 0, false, false, true},             // 85
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 86
+	{json5OpCall, 109, 110, nil, 0, false, false, false},           // 87
+	{json5OpCall, -1, 111, nil, 0, false, false, true},             // 88
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 89
+	{json5OpRune, 112, -1, nil, 0, false, false, false},            // 90
+	{json5OpRune, 113, -1, nil, 0, false, false, false},            // 91
+	{json5OpRune, 114, -1, nil, 0, false, false, false},            // 92
+	{json5OpCall, -1, 115, nil, 0, false, false, true},             // 93
+	// LineComment
+	{json5OpCall, 116, 117, nil, 0, false, false, false}, // 94
+	// BlockComment
+	{json5OpCall, 118, 119, nil, 0, false, false, false},                      // 95
+	{json5OpCall, -1, 120, nil, 0, false, false, false},                       // 96
+	{json5OpClone, -1, -1, []int{-1, 121}, 0, false, false, false},            // 97
+	{json5OpCall, -1, 122, nil, 0, false, false, false},                       // 98
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 99
+	{json5OpCall, -1, 123, nil, 0, false, false, false},                       // 100
+	{json5OpClone, -1, -1, []int{-1, 124}, 0, false, false, false},            // 101
+	{json5OpCall, -1, 125, nil, 0, false, false, false},                       // 102
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 103
+	{json5OpClone, -1, -1, []int{126, 127, 128, 129}, 0, false, false, false}, // 104
+	{json5OpCall, -1, 130, nil, 0, false, false, true},                        // 105
+	{json5OpCall, -1, 131, nil, 0, false, false, false},                       // 106
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 107
+	{json5OpCall, -1, 132, nil, 0, false, false, false},                       // 108
+	{json5OpCall, -1, 133, nil, 0, false, false, false},                       // 109
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                         // 110
+	{json5OpCall, -1, 134, nil, 0, false, false, false},                       // 111
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 112
+	{json5OpRune, 135, -1, nil, 0, false, false, false},                       // 113
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 114
+	{json5OpCall, 136, 137, nil, 0, false, false, false},                      // 115
+	{json5OpCall, 138, 139, nil, 0, false, false, false},                      // 116
+	{json5OpRune, 140, -1, nil, 0, false, false, false},                       // 117
+	{json5OpCall, 141, 142, nil, 0, false, false, false},                      // 118
+	{json5OpRune, 143, -1, nil, 0, false, false, false},                       // 119
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 120
+	{json5OpCall, -1, 144, nil, 0, false, false, true},                        // 121
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 122
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 123
+	{json5OpCall, -1, 145, nil, 0, false, false, true},                        // 124
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 125
+	{json5OpCall, -1, 146, nil, 0, false, false, true},                        // 126
+	{json5OpCall, -1, 147, nil, 0, false, false, true},                        // 127
+	{json5OpCall, -1, 148, nil, 0, false, false, true},                        // 128
+	{json5OpCall, -1, 149, nil, 0, false, false, true},                        // 129
+	{json5OpCall, -1, 150, nil, 0, false, false, false},                       // 130
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 131
+	{json5OpCall, 151, 152, nil, 0, false, false, false},                      // 132
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 133
+	{json5OpCall, 153, 154, nil, 0, false, false, false},                      // 134
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 135
+	{json5OpJump, -1, 71, nil, 0, false, false, false},                        // 136
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 137
+	{json5OpCall, -1, 155, nil, 0, false, false, false},                       // 138
+	{json5OpClone, -1, -1, []int{-1, 156}, 0, false, false, false},            // 139
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 140
+	{json5OpCall, 157, 158, nil, 0, false, false, false},                      // 141
+	{json5OpClone, -1, -1, []int{-1, 159}, 0, false, false, false},            // 142
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 143
+	{json5OpCall, 160, 161, nil, 0, false, false, false},                      // 144
+	{json5OpCall, 162, 163, nil, 0, false, false, false},                      // 145
+	{json5OpRune, 164, -1, nil, 0, false, false, false},                       // 146
+	{json5OpRune, 165, -1, nil, 0, false, false, false},                       // 147
+	{json5OpCall, 166, 167, nil, 0, false, false, false},                      // 148
+	{json5OpCall, 168, 169, nil, 0, false, false, false},                      // 149
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 150
+	{json5OpCall, 170, 171, nil, 0, false, false, false},                      // 151
+	{json5OpCall, -1, 172, nil, 0, false, false, false},                       // 152
+	{json5OpCall, 173, 174, nil, 0, false, false, false},                      // 153
+	{json5OpCall, -1, 5, nil, 0, false, false, false},                         // 154
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 155
+	{json5OpCall, -1, 175, nil, 0, false, false, true},                        // 156
+	{json5OpCall, 176, 177, nil, 0, false, false, false},                      // 157
+	{json5OpCall, 178, 179, nil, 0, false, false, false},                      // 158
+	{json5OpCall, -1, 180, nil, 0, false, false, true},                        // 159
+	{json5OpJump, -1, 97, nil, 0, false, false, false},                        // 160
+	{json5OpClone, -1, -1, []int{181, 182}, 0, false, false, false},           // 161
+	{json5OpJump, -1, 101, nil, 0, false, false, false},                       // 162
+	{json5OpClone, -1, -1, []int{183, 184}, 0, false, false, false},           // 163
+	{json5OpRune, 185, -1, nil, 0, false, false, false},                       // 164
+	{json5OpRune, 186, -1, nil, 0, false, false, false},                       // 165
+	{json5OpCall, 187, 188, nil, 0, false, false, false},                      // 166
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                        // 167
+	{json5OpCall, -1, 189, nil, 0, false, false, false},                       // 168
+	{json5OpClone, -1, -1, []int{190, 191}, 0, false, false, false},           // 169
+	{json5OpCall, -1, 192, nil, 0, false, false, false},                       // 170
+	{json5OpClone, -1, -1, []int{-1, 193}, 0, false, false, false},            // 171
+	// Member
+	{json5OpCall, 194, 195, nil, 0, false, false, false},            // 172
+	{json5OpCall, -1, 196, nil, 0, false, false, false},             // 173
+	{json5OpClone, -1, -1, []int{-1, 197}, 0, false, false, false},  // 174
+	{json5OpCall, 198, 199, nil, 0, false, false, false},            // 175
+	{json5OpCall, -1, 200, nil, 0, false, false, false},             // 176
+	{json5OpClone, -1, -1, []int{-1, 201}, 0, false, false, false},  // 177
+	{json5OpCall, -1, 202, nil, 0, false, false, false},             // 178
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 179
+	{json5OpCall, 203, 204, nil, 0, false, false, false},            // 180
+	{json5OpCall, -1, 205, nil, 0, false, false, true},              // 181
+	{json5OpCall, -1, 206, nil, 0, false, false, true},              // 182
+	{json5OpCall, -1, 207, nil, 0, false, false, true},              // 183
+	{json5OpCall, -1, 208, nil, 0, false, false, true},              // 184
+	{json5OpRune, 209, -1, nil, 0, false, false, false},             // 185
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 186
+	{json5OpCall, -1, 210, nil, 0, false, false, false},             // 187
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 188
+	{json5OpClone, -1, -1, []int{-1, 211}, 0, false, false, false},  // 189
+	{json5OpCall, -1, 212, nil, 0, false, false, true},              // 190
+	{json5OpCall, -1, 213, nil, 0, false, false, true},              // 191
+	{json5OpClone, -1, -1, []int{-1, 214}, 0, false, false, false},  // 192
+	{json5OpCall, -1, 215, nil, 0, false, false, true},              // 193
+	{json5OpCall, 216, 217, nil, 0, false, false, false},            // 194
+	{json5OpClone, -1, -1, []int{218, 219}, 0, false, false, false}, // 195
+	{json5OpClone, -1, -1, []int{-1, 220}, 0, false, false, false},  // 196
+	{json5OpCall, -1, 221, nil, 0, false, false, true},              // 197
+	{json5OpJump, -1, 139, nil, 0, false, false, false},             // 198
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 199
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 200
+	{json5OpCall, -1, 222, nil, 0, false, false, true},              // 201
+	{json5OpClone, -1, -1, []int{-1, 223}, 0, false, false, false},  // 202
+	{json5OpJump, -1, 142, nil, 0, false, false, false},             // 203
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 204
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 205
+	{json5OpCall, -1, 224, nil, 0, false, false, false},             // 206
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 207
+	{json5OpCall, -1, 224, nil, 0, false, false, false},             // 208
+	{json5OpRune, 225, -1, nil, 0, false, false, false},             // 209
+	{json5OpCall, 226, 227, nil, 0, false, false, false},            // 210
+	{json5OpCall, -1, 228, nil, 0, false, false, true},              // 211
+	{json5OpCall, 229, 230, nil, 0, false, false, false},            // 212
+	{json5OpCall, 231, 232, nil, 0, false, false, false},            // 213
+	{json5OpCall, -1, 233, nil, 0, false, false, true},              // 214
+	{json5OpCall, 234, 235, nil, 0, false, false, false},            // 215
+	{json5OpCall, -1, 236, nil, 0, false, false, false},             // 216
+	{json5OpCall, 237, 238, nil, 0, false, false, false},            // 217
+	{json5OpCall, -1, 239, nil, 0, false, false, true},              // 218
+	{json5OpCall, -1, 240, nil, 0, false, false, true},              // 219
+	{json5OpCall, -1, 241, nil, 0, false, false, true},              // 220
+	{json5OpCall, 242, 243, nil, 0, false, false, false},            // 221
+	{json5OpCall, 244, 245, nil, 0, false, false, false},            // 222
+	{json5OpCall, -1, 246, nil, 0, false, false, true},              // 223
+	// Escape
+	{json5OpCall, 247, 248, nil, 0, false, false, false},            // 224
+	{json5OpRune, 249, -1, nil, 0, false, false, false},             // 225
+	{json5OpCall, -1, 250, nil, 0, false, false, false},             // 226
+	{json5OpCall, -1, 251, nil, 0, false, false, false},             // 227
+	{json5OpCall, -1, 252, nil, 0, false, false, false},             // 228
+	{json5OpCall, -1, 253, nil, 0, false, false, false},             // 229
+	{json5OpClone, -1, -1, []int{254, 255}, 0, false, false, false}, // 230
+	{json5OpCall, -1, 256, nil, 0, false, false, false},             // 231
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 232
+	{json5OpCall, -1, 257, nil, 0, false, false, false},             // 233
+	{json5OpJump, -1, 171, nil, 0, false, false, false},             // 234
+	{json5OpCall, 258, 259, nil, 0, false, false, false},            // 235
+	{json5OpCall, -1, 5, nil, 0, false, false, false},               // 236
+	{json5OpCall, -1, 260, nil, 0, false, false, false},             // 237
+	{json5OpCall, -1, 7, nil, 0, false, false, false},               // 238
+	{json5OpCall, -1, 26, nil, 0, false, false, false},              // 239
+	{json5OpCall, 261, 262, nil, 0, false, false, false},            // 240
+	{json5OpCall, -1, 263, nil, 0, false, false, false},             // 241
+	{json5OpJump, -1, 174, nil, 0, false, false, false},             // 242
+	{json5OpCall, 264, 265, nil, 0, false, false, false},            // 243
+	{json5OpJump, -1, 177, nil, 0, false, false, false},             // 244
+	{json5OpCall, 266, 267, nil, 0, false, false, false},            // 245
+	{json5OpCall, 268, 179, nil, 0, false, false, false},            // 246
+	{json5OpCall, -1, 269, nil, 0, false, false, false},             // 247
+	{json5OpRune, -1, -1, nil, 0, false, false, false},              // 248
+	{json5OpRune, 270, -1, nil, 0, false, false, false},             // 249
+	{json5OpClone, -1, -1, []int{-1, 271}, 0, false, false, false},  // 250
+	// HexDigit
+	{json5OpClone, -1, -1, []int{272, 273, 274}, 0, false, false, false},                // 251
+	{json5OpCall, 275, 276, nil, 0, false, false, false},                                // 252
+	{json5OpClone, -1, -1, []int{-1, 277}, 0, false, false, false},                      // 253
+	{json5OpCall, -1, 278, nil, 0, false, false, true},                                  // 254
+	{json5OpCall, -1, 279, nil, 0, false, false, true},                                  // 255
+	{json5OpCall, 280, 281, nil, 0, false, false, false},                                // 256
+	{json5OpCall, 282, 283, nil, 0, false, false, false},                                // 257
+	{json5OpCall, -1, 284, nil, 0, false, false, false},                                 // 258
+	{json5OpCall, 285, 286, nil, 0, false, false, false},                                // 259
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 260
+	{json5OpCall, -1, 287, nil, 0, false, false, false},                                 // 261
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                                   // 262
+	{json5OpCall, 288, 289, nil, 0, false, false, false},                                // 263
+	{json5OpCall, -1, 290, nil, 0, false, false, false},                                 // 264
+	{json5OpCall, 291, 292, nil, 0, false, false, false},                                // 265
+	{json5OpCall, 293, 294, nil, 0, false, false, false},                                // 266
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 267
+	{json5OpJump, -1, 202, nil, 0, false, false, false},                                 // 268
+	{json5OpClone, -1, -1, []int{295, 296, 297, 298, 299, 300}, 0, false, false, false}, // 269
+	{json5OpRune, 301, -1, nil, 0, false, false, false},                                 // 270
+	{json5OpCall, -1, 302, nil, 0, false, false, true},                                  // 271
+	{json5OpCall, -1, 303, nil, 1, true, false, true},                                   // 272
+	{json5OpCall, -1, 304, nil, 1, true, false, true},                                   // 273
+	{json5OpCall, -1, 305, nil, 1, true, false, true},                                   // 274
+	{json5OpCall, 306, 307, nil, 0, false, false, false},                                // 275
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 276
+	{json5OpCall, -1, 308, nil, 0, false, false, true},                                  // 277
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 278
+	{json5OpCall, 309, 310, nil, 0, false, false, false},                                // 279
+	{json5OpCall, -1, 311, nil, 0, false, false, false},                                 // 280
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 281
+	{json5OpCall, -1, 312, nil, 0, false, false, false},                                 // 282
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                                   // 283
+	{json5OpCall, -1, 172, nil, 0, false, false, false},                                 // 284
+	{json5OpCall, -1, 313, nil, 0, false, false, false},                                 // 285
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                                   // 286
+	{json5OpCall, -1, 314, nil, 0, false, false, false},                                 // 287
+	{json5OpCall, -1, 315, nil, 0, false, false, false},                                 // 288
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                                   // 289
+	{json5OpCall, -1, 5, nil, 0, false, false, false},                                   // 290
+	{json5OpCall, -1, 316, nil, 0, false, false, false},                                 // 291
+	{json5OpCall, -1, 7, nil, 0, false, false, false},                                   // 292
+	{json5OpCall, -1, 317, nil, 0, false, false, false},                                 // 293
+	{json5OpClone, -1, -1, []int{-1, 318}, 0, false, false, false},                      // 294
+	{json5OpCall, -1, 319, nil, 0, false, false, true},                                  // 295
+	{json5OpCall, -1, 320, nil, 0, false, false, true},                                  // 296
+	{json5OpCall, -1, 321, nil, 0, false, false, true},                                  // 297
+	{json5OpCall, -1, 322, nil, 0, false, false, true},                                  // 298
+	{json5OpCall, -1, 323, nil, 0, false, false, true},                                  // 299
+	{json5OpCall, -1, 324, nil, 0, false, false, true},                                  // 300
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 301
+	{json5OpCall, 325, 227, nil, 0, false, false, false},                                // 302
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 303
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 304
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 305
+	{json5OpCall, -1, 326, nil, 0, false, false, false},                                 // 306
+	{json5OpClone, -1, -1, []int{-1, 327}, 0, false, false, false},                      // 307
+	{json5OpCall, -1, 328, nil, 0, false, false, false},                                 // 308
+	{json5OpCall, -1, 329, nil, 0, false, false, false},                                 // 309
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 310
+	{json5OpClone, -1, -1, []int{-1, 330}, 0, false, false, false},                      // 311
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 312
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                                  // 313
+	// Identifier
+	{json5OpCall, 331, 332, nil, 0, false, false, false},           // 314
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 315
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 316
+	{json5OpCall, 333, 334, nil, 0, false, false, false},           // 317
+	{json5OpCall, -1, 335, nil, 0, false, false, true},             // 318
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 319
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 320
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 321
+	{json5OpRune, 336, -1, nil, 0, false, false, false},            // 322
+	{json5OpCall, 337, 338, nil, 0, false, false, false},           // 323
+	{json5OpCall, 339, 340, nil, 0, false, false, false},           // 324
+	{json5OpJump, -1, 250, nil, 0, false, false, false},            // 325
+	{json5OpCall, 341, 342, nil, 0, false, false, false},           // 326
+	{json5OpCall, -1, 343, nil, 0, false, false, true},             // 327
+	{json5OpCall, 344, 345, nil, 0, false, false, false},           // 328
+	{json5OpClone, -1, -1, []int{-1, 346}, 0, false, false, false}, // 329
+	{json5OpCall, -1, 347, nil, 0, false, false, true},             // 330
+	{json5OpCall, -1, 348, nil, 0, false, false, false},            // 331
+	{json5OpCall, -1, 349, nil, 0, false, false, false},            // 332
+	{json5OpCall, -1, 350, nil, 0, false, false, false},            // 333
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 334
+	{json5OpCall, 351, 352, nil, 0, false, false, false},           // 335
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 336
+	{json5OpCall, 353, 354, nil, 0, false, false, false},           // 337
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 338
+	{json5OpCall, 355, 356, nil, 0, false, false, false},           // 339
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 340
+	{json5OpCall, -1, 357, nil, 0, false, false, false},            // 341
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 342
+	{json5OpCall, -1, 358, nil, 0, false, false, false},            // 343
+	{json5OpCall, -1, 359, nil, 0, false, false, false},            // 344
+	{json5OpRune, -1, -1, nil, 0, false, false, false},             // 345
+	{json5OpCall, -1, 360, nil, 0, false, false, true},             // 346
+	{json5OpCall, 361, 281, nil, 0, false, false, false},           // 347
+	{json5OpClone, -1, -1, []int{-1, 362}, 0, false, false, false}, // 348
+	// IdentifierStart
+	{json5OpClone, -1, -1, []int{363, 364, 365, 366}, 0, false, false, false},      // 349
+	{json5OpClone, -1, -1, []int{-1, 367}, 0, false, false, false},                 // 350
+	{json5OpJump, -1, 294, nil, 0, false, false, false},                            // 351
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 352
+	{json5OpCall, -1, 368, nil, 0, false, false, false},                            // 353
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 354
+	{json5OpCall, 369, 370, nil, 0, false, false, false},                           // 355
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 356
+	{json5OpClone, -1, -1, []int{-1, 371}, 0, false, false, false},                 // 357
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 358
+	{json5OpClone, -1, -1, []int{-1, 372}, 0, false, false, false},                 // 359
+	{json5OpCall, 373, 374, nil, 0, false, false, false},                           // 360
+	{json5OpJump, -1, 311, nil, 0, false, false, false},                            // 361
+	{json5OpCall, -1, 375, nil, 0, false, false, true},                             // 362
+	{json5OpCall, -1, 376, nil, 0, false, false, true},                             // 363
+	{json5OpCall, -1, 377, nil, 0, false, false, true},                             // 364
+	{json5OpCall, -1, 378, nil, 0, false, false, true},                             // 365
+	{json5OpCall, -1, 379, nil, 0, false, false, true},                             // 366
+	{json5OpCall, -1, 380, nil, 0, false, false, true},                             // 367
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 368
+	{json5OpCall, 381, 382, nil, 0, false, false, false},                           // 369
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 370
+	{json5OpCall, -1, 383, nil, 0, false, false, true},                             // 371
+	{json5OpCall, -1, 384, nil, 0, false, false, true},                             // 372
+	{json5OpJump, -1, 329, nil, 0, false, false, false},                            // 373
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 374
+	{json5OpCall, 385, 386, nil, 0, false, false, false},                           // 375
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 376
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 377
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 378
+	{json5OpCall, 387, 388, nil, 0, false, false, false},                           // 379
+	{json5OpCall, 389, 334, nil, 0, false, false, false},                           // 380
+	{json5OpCall, -1, 390, nil, 0, false, false, false},                            // 381
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 382
+	{json5OpCall, 391, 342, nil, 0, false, false, false},                           // 383
+	{json5OpCall, 392, 393, nil, 0, false, false, false},                           // 384
+	{json5OpJump, -1, 348, nil, 0, false, false, false},                            // 385
+	{json5OpClone, -1, -1, []int{394, 395, 396, 397, 398}, 0, false, false, false}, // 386
+	{json5OpCall, 399, 400, nil, 0, false, false, false},                           // 387
+	{json5OpRune, 401, -1, nil, 0, false, false, false},                            // 388
+	{json5OpJump, -1, 350, nil, 0, false, false, false},                            // 389
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 390
+	{json5OpJump, -1, 357, nil, 0, false, false, false},                            // 391
+	{json5OpJump, -1, 359, nil, 0, false, false, false},                            // 392
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 393
+	{json5OpCall, -1, 402, nil, 0, false, false, true},                             // 394
+	{json5OpCall, -1, 403, nil, 0, false, false, true},                             // 395
+	{json5OpCall, -1, 404, nil, 0, false, false, true},                             // 396
+	{json5OpCall, -1, 405, nil, 0, false, false, true},                             // 397
+	{json5OpCall, -1, 406, nil, 0, false, false, true},                             // 398
+	{json5OpCall, 407, 408, nil, 0, false, false, false},                           // 399
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 400
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 401
+	{json5OpCall, -1, 349, nil, 0, false, false, false},                            // 402
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 403
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 404
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 405
+	{json5OpRune, -1, -1, nil, 0, false, false, false},                             // 406
+	{json5OpCall, 409, 410, nil, 0, false, false, false},                           // 407
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 408
+	{json5OpCall, -1, 411, nil, 0, false, false, false},                            // 409
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 410
+	{json5OpCall, -1, 251, nil, 0, false, false, false},                            // 411
+}
+
+func json5MatchRune(pc int, r rune) bool {
+	switch pc {
+	case 47:
+		return r == 't'
+	case 48:
+		return r == 'f'
+	case 49:
+		return r == 'n'
+	case 51:
+		return r == '/'
+	case 68:
+		return r == 'r'
+	case 69:
+		return r == 'a'
+	case 70:
+		return r == 'u'
+	case 72:
+		return r == '/'
+	case 73:
+		switch r {
+		case '\t', '\n', '\v', '\f', '\r', ' ', 0xa0, 0x2028, 0x2029, 0xfeff:
+			return true
+		}
+		return false
+	case 74:
+		switch r {
+		case 0x1680, 0x202f, 0x205f, 0x3000:
+			return true
+		}
+		return false
+	case 75:
+		return r >= 0x2000 && r <= 0x200a
+	case 86:
+		return r == '{'
+	case 89:
+		return r == '['
+	case 90:
+		return r == 'u'
+	case 91:
+		return r == 'l'
+	case 92:
+		return r == 'l'
+	case 112:
+		return r == 'e'
+	case 113:
+		return r == 's'
+	case 114:
+		return r == 'l'
+	case 117:
+		return r == '/'
+	case 119:
+		return r == '/'
+	case 120:
+		return r == '"'
+	case 122:
+		return r == '"'
+	case 123:
+		return r == '\''
+	case 125:
+		return r == '\''
+	case 131:
+		return r == '}'
+	case 133:
+		return r == ']'
+	case 135:
+		return r == 'e'
+	case 137:
+		return r != '\n'
+	case 140:
+		return r == '/'
+	case 143:
+		return r == '*'
+	case 146:
+		return r == 'I'
+	case 147:
+		return r == 'N'
+	case 150:
+		switch r {
+		case '+', '-':
+			return true
+		}
+		return false
+	case 155:
+		return r == '\n'
+	case 164:
+		return r == 'n'
+	case 165:
+		return r == 'a'
+	case 167:
+		return r == '0'
+	case 179:
+		return r == '*'
+	case 185:
+		return r == 'f'
+	case 186:
+		return r == 'N'
+	case 188:
+		switch r {
+		case 'X', 'x':
+			return true
+		}
+		return false
+	case 199:
+		return r != '\n'
+	case 200:
+		return r == '/'
+	case 204:
+		return r != '*'
+	case 205:
+		switch r {
+		case '\n', '\r', '"', '\\':
+			return false
+		}
+		return true
+	case 207:
+		switch r {
+		case '\n', '\r', '\'', '\\':
+			return false
+		}
+		return true
+	case 209:
+		return r == 'i'
+	case 225:
+		return r == 'n'
+	case 232:
+		return r == '.'
+	case 248:
+		return r == '\\'
+	case 249:
+		return r == 'i'
+	case 260:
+		return r == ':'
+	case 267:
+		switch r {
+		case '*', '/':
+			return false
+		}
+		return true
+	case 270:
+		return r == 't'
+	case 276:
+		switch r {
+		case 'E', 'e':
+			return true
+		}
+		return false
+	case 278:
+		return r == '0'
+	case 281:
+		return r >= '0' && r <= '9'
+	case 301:
+		return r == 'y'
+	case 303:
+		return r >= '0' && r <= '9'
+	case 304:
+		return r >= 'a' && r <= 'f'
+	case 305:
+		return r >= 'A' && r <= 'F'
+	case 310:
+		return r >= '1' && r <= '9'
+	case 312:
+		return r == ','
+	case 313:
+		return r == ','
+	case 315:
+		return r == ','
+	case 316:
+		return r == ','
+	case 319:
+		switch r {
+		case '\n', '\r', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'u', 'x':
+			return false
+		}
+		return true
+	case 320:
+		return r == '\n'
+	case 321:
+		return r == '\r'
+	case 322:
+		return r == '\r'
+	case 334:
+		return r == '*'
+	case 336:
+		return r == '\n'
+	case 338:
+		return r == 'x'
+	case 340:
+		return r == 'u'
+	case 342:
+		return r >= '0' && r <= '9'
+	case 345:
+		return r == '.'
+	case 352:
+		return r != '*'
+	case 358:
+		switch r {
+		case '+', '-':
+			return true
+		}
+		return false
+	case 374:
+		return r >= '0' && r <= '9'
+	case 376:
+		return unicode.Is(json5CategoryL, r)
+	case 377:
+		return unicode.Is(json5CategoryNl, r)
+	case 378:
+		switch r {
+		case '$', '_':
+			return true
+		}
+		return false
+	case 388:
+		return r == '\\'
+	case 393:
+		return r >= '0' && r <= '9'
+	case 401:
+		return r == 'u'
+	case 403:
+		return unicode.Is(json5CategoryMn, r)
+	case 404:
+		return unicode.Is(json5CategoryMc, r)
+	case 405:
+		return unicode.Is(json5CategoryNd, r)
+	case 406:
+		switch r {
+		case 0x200c, 0x200d, '‿', '⁀', '⁔', '︳', '︴', '﹍', '﹎', '﹏', '＿':
+			return true
+		}
+		return false
+	}
+	panic("bad instruction")
+}
+
+func NewJSON5() *JSON5 {
+	m := new(JSON5)
+	m.Reset()
+	return m
+}
+
+// Reset restarts matching from the start rule
+func (m *JSON5) Reset() {
+	m.threads = append(m.threads[:0], &json5Thread{
+		pc: json5Start,
+	})
+}
+
+// Alive reports whether there are threads expecting more input
+func (m *JSON5) Alive() bool {
+	return len(m.threads) > 0
+}
+
+// Match reports whether the whole input matches the start rule
+func (m *JSON5) Match(input string) bool {
+	m.Reset()
+	matched := false
+	for len(input) > 0 {
+		r, size := utf8.DecodeRuneInString(input)
+		input = input[size:]
+		matched = m.Step(r)
+		if len(m.threads) == 0 {
+			return matched && len(input) == 0
+		}
+	}
+	return matched
+}
+
+// Step feeds one rune and reports whether any thread matched the input so far
+func (m *JSON5) Step(r rune) bool {
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		thread := m.threads[i]
+		if thread.pc >= 0 && json5Insts[thread.pc].op != json5OpRune {
+			// added by predicting instructions
+			m.prepare(thread)
+		}
+	feed:
+		if thread.pc >= 0 {
+			thread.match = json5MatchRune(thread.pc, r)
+			if thread.match {
+				inst := &json5Insts[thread.pc]
+				if inst.predict {
+					thread.pc = inst.target
+					m.prepare(thread)
+					goto feed
+				}
+				thread.pc = inst.next
+			} else {
+				m.kill(thread)
+			}
+		}
+		thread.stats = thread.stats[:0]
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	matched := false
+	threads := m.threads[:0]
+	for _, thread := range m.threads {
+		if thread.pc < 0 {
+			if thread.match {
+				matched = true
+			}
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	for i := len(threads); i < len(m.threads); i++ {
+		m.threads[i] = nil
+	}
+	m.threads = threads
+
+	return matched
+}
+
+func (m *JSON5) prepare(thread *json5Thread) {
+	for {
+
+		if thread.pc < 0 {
+			// implicit return
+			if len(thread.stack) == 0 {
+				return
+			}
+			m.unwind(thread)
+			continue
+		}
+
+		inst := &json5Insts[thread.pc]
+		if inst.op == json5OpRune {
+			return
+		}
+
+		if !inst.fresh {
+			added := false
+			for i, stat := range thread.stats {
+				if stat.pc == thread.pc {
+					if stat.counter >= json5Bound {
+						m.kill(thread)
+						return
+					}
+					thread.stats[i].counter++
+					added = true
+					break
+				}
+			}
+			if !added {
+				thread.stats = append(thread.stats, json5Stat{
+					pc: thread.pc,
+				})
+			}
+		}
+
+		switch inst.op {
+
+		case json5OpCall:
+			if inst.next >= 0 || inst.cluster > 0 {
+				thread.stack = append(thread.stack, json5Frame{
+					ret:      inst.next,
+					cluster:  inst.cluster,
+					shortest: inst.shortest,
+				})
+			}
+			thread.pc = inst.target
+
+		case json5OpJump:
+			thread.pc = inst.target
+
+		case json5OpClone:
+			for i, pc := range inst.insts {
+				t := thread
+				if i > 0 {
+					t = &json5Thread{
+						stack: append([]json5Frame(nil), thread.stack...),
+						stats: append([]json5Stat(nil), thread.stats...),
+						match: thread.match,
+					}
+					m.threads = append(m.threads, t)
+				}
+				t.pc = pc
+			}
+
+		case json5OpReturn:
+			if len(thread.stack) == 0 {
+				thread.pc = -1
+				return
+			}
+			m.unwind(thread)
+
+		}
+
+	}
+}
+
+func (m *JSON5) unwind(thread *json5Thread) {
+	frame := thread.stack[len(thread.stack)-1]
+	thread.pc = frame.ret
+	thread.stack = thread.stack[:len(thread.stack)-1]
+	if frame.shortest && thread.match {
+		// kill threads in the same cluster
+	loop:
+		for _, t := range m.threads {
+			if t == thread {
+				continue
+			}
+			for _, f := range t.stack {
+				if f.cluster == frame.cluster {
+					m.kill(t)
+					continue loop
+				}
+			}
+		}
+	}
+}
+
+func (m *JSON5) kill(thread *json5Thread) {
+	for len(thread.stack) > 0 {
+		m.unwind(thread)
+	}
+	thread.pc = -1
+	thread.match = false
+}
@@ -0,0 +1,628 @@
+// Code generated by pavgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"unicode/utf8"
+)
+
+// TextGrammar is a lockstep matcher
+type TextGrammar struct {
+	threads []*textGrammarThread
+}
+
+type textGrammarThread struct {
+	stack []textGrammarFrame
+	pc    int
+	match bool
+	stats []textGrammarStat
+}
+
+type textGrammarFrame struct {
+	ret      int
+	cluster  int
+	shortest bool
+}
+
+type textGrammarStat struct {
+	pc      int
+	counter int
+}
+
+type textGrammarInstruction struct {
+	op       uint8
+	next     int
+	target   int
+	insts    []int
+	cluster  int
+	shortest bool
+	predict  bool
+	fresh    bool
+}
+
+const (
+	textGrammarOpRune = iota + 1
+	textGrammarOpCall
+	textGrammarOpJump
+	textGrammarOpClone
+	textGrammarOpReturn
+)
+
+const textGrammarStart = 0
+
+const textGrammarBound = 64
+
+var textGrammarInsts = [...]textGrammarInstruction{
+	// Grammar
+	{textGrammarOpCall, 1, 2, nil, 0, false, false, false},  // 0
+	{textGrammarOpCall, -1, 3, nil, 0, false, false, false}, // 1
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false}, // 2
+	{textGrammarOpCall, 5, 6, nil, 0, false, false, false},  // 3
+	// Spacing
+	{textGrammarOpClone, -1, -1, []int{-1, 7}, 0, false, false, false},  // 4
+	{textGrammarOpCall, -1, 8, nil, 0, false, false, false},             // 5
+	{textGrammarOpCall, -1, 9, nil, 0, false, false, false},             // 6
+	{textGrammarOpCall, -1, 10, nil, 0, false, false, true},             // 7
+	{textGrammarOpClone, -1, -1, []int{-1, 11}, 0, false, false, false}, // 8
+	// Definition
+	{textGrammarOpCall, 12, 13, nil, 0, false, false, false},            // 9
+	{textGrammarOpCall, 14, 15, nil, 0, false, false, false},            // 10
+	{textGrammarOpCall, -1, 16, nil, 0, false, false, true},             // 11
+	{textGrammarOpCall, 17, 18, nil, 0, false, false, false},            // 12
+	{textGrammarOpCall, -1, 19, nil, 0, false, false, false},            // 13
+	{textGrammarOpJump, -1, 4, nil, 0, false, false, false},             // 14
+	{textGrammarOpClone, -1, -1, []int{20, 21}, 0, false, false, false}, // 15
+	{textGrammarOpCall, 22, 6, nil, 0, false, false, false},             // 16
+	{textGrammarOpCall, -1, 23, nil, 0, false, false, false},            // 17
+	{textGrammarOpCall, 24, 25, nil, 0, false, false, false},            // 18
+	// Identifier
+	{textGrammarOpCall, 26, 27, nil, 0, false, false, false}, // 19
+	{textGrammarOpCall, -1, 28, nil, 0, false, false, true},  // 20
+	{textGrammarOpCall, -1, 29, nil, 0, false, false, true},  // 21
+	{textGrammarOpJump, -1, 8, nil, 0, false, false, false},  // 22
+	{textGrammarOpCall, -1, 30, nil, 0, false, false, false}, // 23
+	{textGrammarOpCall, -1, 31, nil, 0, false, false, false}, // 24
+	{textGrammarOpRune, 32, -1, nil, 0, false, false, false}, // 25
+	{textGrammarOpCall, -1, 33, nil, 0, false, false, false}, // 26
+	{textGrammarOpCall, -1, 34, nil, 0, false, false, false}, // 27
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false}, // 28
+	{textGrammarOpCall, 35, 36, nil, 0, false, false, false}, // 29
+	// Expression
+	{textGrammarOpCall, 37, 38, nil, 0, false, false, false}, // 30
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},  // 31
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false}, // 32
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},  // 33
+	// Name
+	{textGrammarOpCall, 39, 40, nil, 0, false, false, false},            // 34
+	{textGrammarOpCall, 41, 42, nil, 0, false, false, false},            // 35
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 36
+	{textGrammarOpCall, -1, 43, nil, 0, false, false, false},            // 37
+	{textGrammarOpCall, -1, 44, nil, 0, false, false, false},            // 38
+	{textGrammarOpCall, 45, 46, nil, 0, false, false, false},            // 39
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 40
+	{textGrammarOpCall, -1, 47, nil, 0, false, false, false},            // 41
+	{textGrammarOpClone, -1, -1, []int{-1, 48}, 0, false, false, false}, // 42
+	{textGrammarOpClone, -1, -1, []int{-1, 49}, 0, false, false, false}, // 43
+	// Sequence
+	{textGrammarOpCall, 50, 51, nil, 0, false, false, false},            // 44
+	{textGrammarOpCall, -1, 52, nil, 0, false, false, false},            // 45
+	{textGrammarOpClone, -1, -1, []int{-1, 53}, 0, false, false, false}, // 46
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 47
+	{textGrammarOpCall, -1, 54, nil, 0, false, false, true},             // 48
+	{textGrammarOpCall, -1, 55, nil, 0, false, false, true},             // 49
+	{textGrammarOpCall, -1, 56, nil, 0, false, false, false},            // 50
+	{textGrammarOpCall, -1, 57, nil, 0, false, false, false},            // 51
+	{textGrammarOpRune, -1, -1, nil, 0, false, true, false},             // 52
+	{textGrammarOpCall, -1, 58, nil, 0, false, false, true},             // 53
+	{textGrammarOpCall, 59, 60, nil, 0, false, false, false},            // 54
+	{textGrammarOpCall, 61, 62, nil, 0, false, false, false},            // 55
+	{textGrammarOpClone, -1, -1, []int{-1, 63}, 0, false, false, false}, // 56
+	// Prefix
+	{textGrammarOpCall, 64, 65, nil, 0, false, false, false},            // 57
+	{textGrammarOpCall, 66, 67, nil, 0, false, false, false},            // 58
+	{textGrammarOpJump, -1, 42, nil, 0, false, false, false},            // 59
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 60
+	{textGrammarOpJump, -1, 43, nil, 0, false, false, false},            // 61
+	{textGrammarOpCall, 68, 69, nil, 0, false, false, false},            // 62
+	{textGrammarOpCall, -1, 70, nil, 0, false, false, true},             // 63
+	{textGrammarOpCall, -1, 71, nil, 0, false, false, false},            // 64
+	{textGrammarOpClone, -1, -1, []int{-1, 72}, 0, false, false, false}, // 65
+	{textGrammarOpJump, -1, 46, nil, 0, false, false, false},            // 66
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 67
+	{textGrammarOpCall, 73, 74, nil, 0, false, false, false},            // 68
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},            // 69
+	{textGrammarOpCall, 75, 51, nil, 0, false, false, false},            // 70
+	{textGrammarOpCall, -1, 76, nil, 0, false, false, false},            // 71
+	{textGrammarOpCall, -1, 77, nil, 0, false, false, true},             // 72
+	{textGrammarOpCall, -1, 78, nil, 0, false, false, false},            // 73
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},             // 74
+	{textGrammarOpJump, -1, 56, nil, 0, false, false, false},            // 75
+	// Suffix
+	{textGrammarOpCall, 79, 80, nil, 0, false, false, false},            // 76
+	{textGrammarOpCall, -1, 81, nil, 0, false, false, false},            // 77
+	{textGrammarOpCall, -1, 44, nil, 0, false, false, false},            // 78
+	{textGrammarOpCall, -1, 82, nil, 0, false, false, false},            // 79
+	{textGrammarOpCall, -1, 83, nil, 0, false, false, false},            // 80
+	{textGrammarOpCall, -1, 84, nil, 0, false, false, false},            // 81
+	{textGrammarOpClone, -1, -1, []int{-1, 85}, 0, false, false, false}, // 82
+	// Primary
+	{textGrammarOpClone, -1, -1, []int{86, 87, 88, 89, 90}, 0, false, false, false}, // 83
+	// Predicate
+	{textGrammarOpCall, 91, 92, nil, 0, false, false, false},   // 84
+	{textGrammarOpCall, -1, 93, nil, 0, false, false, true},    // 85
+	{textGrammarOpCall, -1, 94, nil, 0, false, false, true},    // 86
+	{textGrammarOpCall, -1, 95, nil, 0, false, false, true},    // 87
+	{textGrammarOpCall, -1, 96, nil, 0, false, false, true},    // 88
+	{textGrammarOpCall, -1, 97, nil, 0, false, false, true},    // 89
+	{textGrammarOpCall, -1, 98, nil, 0, false, false, true},    // 90
+	{textGrammarOpCall, -1, 99, nil, 0, false, false, false},   // 91
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},   // 92
+	{textGrammarOpCall, -1, 100, nil, 0, false, false, false},  // 93
+	{textGrammarOpCall, -1, 19, nil, 0, false, false, false},   // 94
+	{textGrammarOpCall, 101, 102, nil, 0, false, false, false}, // 95
+	{textGrammarOpCall, -1, 103, nil, 0, false, false, false},  // 96
+	{textGrammarOpCall, -1, 104, nil, 0, false, false, false},  // 97
+	{textGrammarOpCall, -1, 105, nil, 0, false, false, false},  // 98
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},    // 99
+	{textGrammarOpCall, -1, 106, nil, 0, false, false, false},  // 100
+	{textGrammarOpCall, 107, 108, nil, 0, false, false, false}, // 101
+	{textGrammarOpCall, 109, 110, nil, 0, false, false, false}, // 102
+	// Literal
+	{textGrammarOpCall, 111, 112, nil, 0, false, false, false}, // 103
+	// Class
+	{textGrammarOpCall, 113, 114, nil, 0, false, false, false}, // 104
+	// Dot
+	{textGrammarOpCall, 115, 116, nil, 0, false, false, false}, // 105
+	// Quantifier
+	{textGrammarOpCall, 117, 118, nil, 0, false, false, false},            // 106
+	{textGrammarOpCall, -1, 119, nil, 0, false, false, false},             // 107
+	{textGrammarOpCall, -1, 30, nil, 0, false, false, false},              // 108
+	{textGrammarOpCall, -1, 120, nil, 0, false, false, false},             // 109
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 110
+	{textGrammarOpCall, -1, 121, nil, 0, false, false, false},             // 111
+	{textGrammarOpClone, -1, -1, []int{122, 123}, 0, false, false, false}, // 112
+	{textGrammarOpCall, 124, 125, nil, 0, false, false, false},            // 113
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 114
+	{textGrammarOpCall, -1, 126, nil, 0, false, false, false},             // 115
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 116
+	{textGrammarOpCall, -1, 127, nil, 0, false, false, false},             // 117
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 118
+	{textGrammarOpCall, 128, 129, nil, 0, false, false, false},            // 119
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 120
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 121
+	{textGrammarOpCall, -1, 130, nil, 0, false, false, true},              // 122
+	{textGrammarOpCall, -1, 131, nil, 0, false, false, true},              // 123
+	{textGrammarOpCall, 132, 133, nil, 0, false, false, false},            // 124
+	{textGrammarOpClone, -1, -1, []int{-1, 134}, 0, false, false, false},  // 125
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 126
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 127
+	{textGrammarOpCall, -1, 135, nil, 0, false, false, false},             // 128
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 129
+	{textGrammarOpCall, 136, 137, nil, 0, false, false, false},            // 130
+	{textGrammarOpCall, 138, 139, nil, 0, false, false, false},            // 131
+	{textGrammarOpCall, -1, 140, nil, 0, false, false, false},             // 132
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 133
+	{textGrammarOpCall, -1, 141, nil, 0, false, false, true},              // 134
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 135
+	{textGrammarOpCall, 142, 143, nil, 0, false, false, false},            // 136
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 137
+	{textGrammarOpCall, 144, 145, nil, 0, false, false, false},            // 138
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 139
+	{textGrammarOpCall, -1, 4, nil, 0, false, false, false},               // 140
+	{textGrammarOpCall, -1, 146, nil, 0, false, false, false},             // 141
+	{textGrammarOpCall, -1, 147, nil, 0, false, false, false},             // 142
+	{textGrammarOpClone, -1, -1, []int{-1, 148}, 0, false, false, false},  // 143
+	{textGrammarOpCall, -1, 149, nil, 0, false, false, false},             // 144
+	{textGrammarOpClone, -1, -1, []int{-1, 150}, 0, false, false, false},  // 145
+	{textGrammarOpClone, -1, -1, []int{151, 152}, 0, false, false, false}, // 146
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 147
+	{textGrammarOpCall, -1, 153, nil, 0, false, false, true},              // 148
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 149
+	{textGrammarOpCall, -1, 154, nil, 0, false, false, true},              // 150
+	{textGrammarOpCall, -1, 155, nil, 0, false, false, true},              // 151
+	{textGrammarOpCall, -1, 156, nil, 0, false, false, true},              // 152
+	{textGrammarOpCall, 157, 158, nil, 0, false, false, false},            // 153
+	{textGrammarOpCall, 159, 160, nil, 0, false, false, false},            // 154
+	{textGrammarOpCall, 161, 162, nil, 0, false, false, false},            // 155
+	{textGrammarOpRune, -1, 163, nil, 0, false, true, false},              // 156
+	{textGrammarOpJump, -1, 143, nil, 0, false, false, false},             // 157
+	{textGrammarOpRune, -1, 164, nil, 0, false, true, false},              // 158
+	{textGrammarOpJump, -1, 145, nil, 0, false, false, false},             // 159
+	{textGrammarOpRune, -1, 165, nil, 0, false, true, false},              // 160
+	{textGrammarOpCall, -1, 166, nil, 0, false, false, false},             // 161
+	{textGrammarOpCall, -1, 167, nil, 0, false, false, false},             // 162
+	{textGrammarOpCall, -1, 168, nil, 0, false, false, false},             // 163
+	{textGrammarOpCall, -1, 169, nil, 0, false, false, false},             // 164
+	{textGrammarOpCall, -1, 169, nil, 0, false, false, false},             // 165
+	{textGrammarOpClone, -1, -1, []int{-1, 170}, 0, false, false, false},  // 166
+	// Negate
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false}, // 167
+	// ClassBody
+	{textGrammarOpClone, -1, -1, []int{171, 172}, 0, false, false, false}, // 168
+	// Char
+	{textGrammarOpClone, -1, -1, []int{173, 174}, 0, false, false, false}, // 169
+	{textGrammarOpCall, -1, 175, nil, 0, false, false, true},              // 170
+	{textGrammarOpCall, -1, 176, nil, 0, false, false, true},              // 171
+	{textGrammarOpCall, -1, 177, nil, 0, false, false, true},              // 172
+	{textGrammarOpCall, -1, 178, nil, 0, false, false, true},              // 173
+	{textGrammarOpCall, -1, 179, nil, 0, false, false, true},              // 174
+	{textGrammarOpCall, -1, 180, nil, 0, false, false, false},             // 175
+	{textGrammarOpCall, 181, 182, nil, 0, false, false, false},            // 176
+	{textGrammarOpCall, 183, 184, nil, 0, false, false, false},            // 177
+	{textGrammarOpCall, 185, 186, nil, 0, false, false, false},            // 178
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 179
+	{textGrammarOpCall, -1, 168, nil, 0, false, false, false},             // 180
+	{textGrammarOpCall, -1, 187, nil, 0, false, false, false},             // 181
+	{textGrammarOpCall, -1, 188, nil, 0, false, false, false},             // 182
+	{textGrammarOpCall, -1, 189, nil, 0, false, false, false},             // 183
+	{textGrammarOpCall, 190, 191, nil, 0, false, false, false},            // 184
+	{textGrammarOpCall, -1, 192, nil, 0, false, false, false},             // 185
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 186
+	{textGrammarOpClone, -1, -1, []int{-1, 193}, 0, false, false, false},  // 187
+	// Dash
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},              // 188
+	{textGrammarOpClone, -1, -1, []int{-1, 194}, 0, false, false, false},  // 189
+	{textGrammarOpCall, -1, 195, nil, 0, false, false, false},             // 190
+	{textGrammarOpCall, -1, 196, nil, 0, false, false, false},             // 191
+	{textGrammarOpClone, -1, -1, []int{197, 198}, 0, false, false, false}, // 192
+	{textGrammarOpCall, -1, 199, nil, 0, false, false, true},              // 193
+	{textGrammarOpCall, -1, 200, nil, 0, false, false, true},              // 194
+	{textGrammarOpClone, -1, -1, []int{-1, 201}, 0, false, false, false},  // 195
+	// Range
+	{textGrammarOpCall, 202, 203, nil, 0, false, false, false},           // 196
+	{textGrammarOpCall, -1, 204, nil, 0, false, false, true},             // 197
+	{textGrammarOpCall, -1, 205, nil, 0, false, false, true},             // 198
+	{textGrammarOpCall, 206, 207, nil, 0, false, false, false},           // 199
+	{textGrammarOpCall, -1, 208, nil, 0, false, false, false},            // 200
+	{textGrammarOpCall, -1, 209, nil, 0, false, false, true},             // 201
+	{textGrammarOpCall, -1, 210, nil, 0, false, false, false},            // 202
+	{textGrammarOpCall, -1, 211, nil, 0, false, false, false},            // 203
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},             // 204
+	{textGrammarOpCall, 212, 213, nil, 0, false, false, false},           // 205
+	{textGrammarOpJump, -1, 187, nil, 0, false, false, false},            // 206
+	{textGrammarOpCall, -1, 196, nil, 0, false, false, false},            // 207
+	{textGrammarOpCall, -1, 188, nil, 0, false, false, false},            // 208
+	{textGrammarOpCall, 214, 191, nil, 0, false, false, false},           // 209
+	{textGrammarOpClone, -1, -1, []int{-1, 215}, 0, false, false, false}, // 210
+	// ClassChar
+	{textGrammarOpRune, -1, 216, nil, 0, false, true, false},   // 211
+	{textGrammarOpCall, 217, 218, nil, 0, false, false, false}, // 212
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},   // 213
+	{textGrammarOpJump, -1, 195, nil, 0, false, false, false},  // 214
+	{textGrammarOpCall, -1, 219, nil, 0, false, false, true},   // 215
+	{textGrammarOpCall, -1, 169, nil, 0, false, false, false},  // 216
+	{textGrammarOpCall, 220, 221, nil, 0, false, false, false}, // 217
+	{textGrammarOpCall, -1, 222, nil, 0, false, false, false},  // 218
+	{textGrammarOpCall, -1, 223, nil, 0, false, false, false},  // 219
+	{textGrammarOpCall, 224, 225, nil, 0, false, false, false}, // 220
+	{textGrammarOpCall, -1, 222, nil, 0, false, false, false},  // 221
+	// HexDigit
+	{textGrammarOpClone, -1, -1, []int{226, 227, 228}, 0, false, false, false}, // 222
+	{textGrammarOpCall, 229, 230, nil, 0, false, false, false},                 // 223
+	{textGrammarOpCall, -1, 231, nil, 0, false, false, false},                  // 224
+	{textGrammarOpCall, -1, 222, nil, 0, false, false, false},                  // 225
+	{textGrammarOpCall, -1, 232, nil, 1, true, false, true},                    // 226
+	{textGrammarOpCall, -1, 233, nil, 1, true, false, true},                    // 227
+	{textGrammarOpCall, -1, 234, nil, 1, true, false, true},                    // 228
+	{textGrammarOpCall, -1, 235, nil, 0, false, false, false},                  // 229
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},                   // 230
+	{textGrammarOpCall, -1, 222, nil, 0, false, false, false},                  // 231
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},                   // 232
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},                   // 233
+	{textGrammarOpRune, -1, -1, nil, 0, false, false, false},                   // 234
+	{textGrammarOpCall, -1, 211, nil, 0, false, false, false},                  // 235
+}
+
+func textGrammarMatchRune(pc int, r rune) bool {
+	switch pc {
+	case 25:
+		return r == '<'
+	case 28:
+		switch r {
+		case '\t', '\n', '\r', ' ':
+			return true
+		}
+		return false
+	case 32:
+		return r == '-'
+	case 36:
+		return r == '#'
+	case 40:
+		switch r {
+		case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '_', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z':
+			return true
+		}
+		return false
+	case 47:
+		return r == '\n'
+	case 52:
+		switch r {
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '_', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z':
+			return false
+		}
+		return true
+	case 60:
+		return r != '\n'
+	case 67:
+		switch r {
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '_', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z':
+			return true
+		}
+		return false
+	case 69:
+		switch r {
+		case '/', '|':
+			return true
+		}
+		return false
+	case 92:
+		switch r {
+		case '!', '&':
+			return true
+		}
+		return false
+	case 110:
+		return r == '('
+	case 114:
+		return r == '['
+	case 116:
+		return r == '.'
+	case 118:
+		switch r {
+		case '*', '+', '?':
+			return true
+		}
+		return false
+	case 129:
+		return r == ')'
+	case 133:
+		return r == ']'
+	case 137:
+		return r == '\''
+	case 139:
+		return r == '"'
+	case 147:
+		return r == '\''
+	case 149:
+		return r == '"'
+	case 156:
+		switch r {
+		case ']', '^':
+			return false
+		}
+		return true
+	case 158:
+		return r != '\''
+	case 160:
+		return r != '"'
+	case 167:
+		return r == '^'
+	case 179:
+		switch r {
+		case '\n', '\\':
+			return false
+		}
+		return true
+	case 186:
+		return r == '\\'
+	case 188:
+		return r == '-'
+	case 204:
+		switch r {
+		case '"', '\'', '-', '[', '\\', ']', 'n', 'r', 't':
+			return true
+		}
+		return false
+	case 211:
+		switch r {
+		case '-', ']':
+			return false
+		}
+		return true
+	case 213:
+		return r == 'u'
+	case 230:
+		return r == '-'
+	case 232:
+		return r >= '0' && r <= '9'
+	case 233:
+		return r >= 'a' && r <= 'f'
+	case 234:
+		return r >= 'A' && r <= 'F'
+	}
+	panic("bad instruction")
+}
+
+func NewTextGrammar() *TextGrammar {
+	m := new(TextGrammar)
+	m.Reset()
+	return m
+}
+
+// Reset restarts matching from the start rule
+func (m *TextGrammar) Reset() {
+	m.threads = append(m.threads[:0], &textGrammarThread{
+		pc: textGrammarStart,
+	})
+}
+
+// Alive reports whether there are threads expecting more input
+func (m *TextGrammar) Alive() bool {
+	return len(m.threads) > 0
+}
+
+// Match reports whether the whole input matches the start rule
+func (m *TextGrammar) Match(input string) bool {
+	m.Reset()
+	matched := false
+	for len(input) > 0 {
+		r, size := utf8.DecodeRuneInString(input)
+		input = input[size:]
+		matched = m.Step(r)
+		if len(m.threads) == 0 {
+			return matched && len(input) == 0
+		}
+	}
+	return matched
+}
+
+// Step feeds one rune and reports whether any thread matched the input so far
+func (m *TextGrammar) Step(r rune) bool {
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		thread := m.threads[i]
+		if thread.pc >= 0 && textGrammarInsts[thread.pc].op != textGrammarOpRune {
+			// added by predicting instructions
+			m.prepare(thread)
+		}
+	feed:
+		if thread.pc >= 0 {
+			thread.match = textGrammarMatchRune(thread.pc, r)
+			if thread.match {
+				inst := &textGrammarInsts[thread.pc]
+				if inst.predict {
+					thread.pc = inst.target
+					m.prepare(thread)
+					goto feed
+				}
+				thread.pc = inst.next
+			} else {
+				m.kill(thread)
+			}
+		}
+		thread.stats = thread.stats[:0]
+	}
+
+	for i := 0; i < len(m.threads); i++ {
+		m.prepare(m.threads[i])
+	}
+
+	matched := false
+	threads := m.threads[:0]
+	for _, thread := range m.threads {
+		if thread.pc < 0 {
+			if thread.match {
+				matched = true
+			}
+			continue
+		}
+		threads = append(threads, thread)
+	}
+	for i := len(threads); i < len(m.threads); i++ {
+		m.threads[i] = nil
+	}
+	m.threads = threads
+
+	return matched
+}
+
+func (m *TextGrammar) prepare(thread *textGrammarThread) {
+	for {
+
+		if thread.pc < 0 {
+			// implicit return
+			if len(thread.stack) == 0 {
+				return
+			}
+			m.unwind(thread)
+			continue
+		}
+
+		inst := &textGrammarInsts[thread.pc]
+		if inst.op == textGrammarOpRune {
+			return
+		}
+
+		if !inst.fresh {
+			added := false
+			for i, stat := range thread.stats {
+				if stat.pc == thread.pc {
+					if stat.counter >= textGrammarBound {
+						m.kill(thread)
+						return
+					}
+					thread.stats[i].counter++
+					added = true
+					break
+				}
+			}
+			if !added {
+				thread.stats = append(thread.stats, textGrammarStat{
+					pc: thread.pc,
+				})
+			}
+		}
+
+		switch inst.op {
+
+		case textGrammarOpCall:
+			if inst.next >= 0 || inst.cluster > 0 {
+				thread.stack = append(thread.stack, textGrammarFrame{
+					ret:      inst.next,
+					cluster:  inst.cluster,
+					shortest: inst.shortest,
+				})
+			}
+			thread.pc = inst.target
+
+		case textGrammarOpJump:
+			thread.pc = inst.target
+
+		case textGrammarOpClone:
+			for i, pc := range inst.insts {
+				t := thread
+				if i > 0 {
+					t = &textGrammarThread{
+						stack: append([]textGrammarFrame(nil), thread.stack...),
+						stats: append([]textGrammarStat(nil), thread.stats...),
+						match: thread.match,
+					}
+					m.threads = append(m.threads, t)
+				}
+				t.pc = pc
+			}
+
+		case textGrammarOpReturn:
+			if len(thread.stack) == 0 {
+				thread.pc = -1
+				return
+			}
+			m.unwind(thread)
+
+		}
+
+	}
+}
+
+func (m *TextGrammar) unwind(thread *textGrammarThread) {
+	frame := thread.stack[len(thread.stack)-1]
+	thread.pc = frame.ret
+	thread.stack = thread.stack[:len(thread.stack)-1]
+	if frame.shortest && thread.match {
+		// kill threads in the same cluster
+	loop:
+		for _, t := range m.threads {
+			if t == thread {
+				continue
+			}
+			for _, f := range t.stack {
+				if f.cluster == frame.cluster {
+					m.kill(t)
+					continue loop
+				}
+			}
+		}
+	}
+}
+
+func (m *TextGrammar) kill(thread *textGrammarThread) {
+	for len(thread.stack) > 0 {
+		m.unwind(thread)
+	}
+	thread.pc = -1
+	thread.match = false
+}
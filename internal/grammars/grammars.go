@@ -0,0 +1,59 @@
+// Package grammars loads builtin grammar objects and text grammar files for commands
+package grammars
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/reusee/pav"
+)
+
+type builtin struct {
+	object interface{}
+	start  string
+}
+
+var builtins = map[string]builtin{
+	"json":    {new(pav.JSONParser), "Text"},
+	"jsonc":   {new(pav.JSONCParser), "Text"},
+	"json5":   {new(pav.JSON5Parser), "Text"},
+	"golexer": {new(pav.GoLexer), "Program"},
+	"text":    {new(pav.TextGrammarParser), "Grammar"},
+	"abnf":    {new(pav.ABNFParser), "Rulelist"},
+}
+
+// Names returns sorted names of builtin grammars
+func Names() []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns routines of a builtin grammar or a text grammar file with .pav or .abnf extension,
+// and the default start rule, which is empty for text grammars
+func Load(grammar string) (routines map[string]pav.Routine, start string, err error) {
+	if b, ok := builtins[grammar]; ok {
+		return pav.ObjectRoutines(b.object), b.start, nil
+	}
+	src, err := ioutil.ReadFile(grammar)
+	if err != nil {
+		return nil, "", err
+	}
+	switch filepath.Ext(grammar) {
+	case ".pav":
+		routines, err = pav.CompileTextGrammar(string(src))
+	case ".abnf":
+		routines, err = pav.CompileABNF(string(src))
+	default:
+		return nil, "", fmt.Errorf("unknown grammar: %s", grammar)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%s:%v", grammar, err)
+	}
+	return routines, "", nil
+}
@@ -0,0 +1,183 @@
+package pav
+
+// JSON5Parser accepts JSON5 documents
+type JSON5Parser struct {
+	JSONCParser
+}
+
+func (_ JSON5Parser) Blank() *Instruction {
+	return ZeroOrMore(
+		Longest(
+			RuneSet(
+				'\t', '\n', '\v', '\f', '\r', ' ',
+				'\u00a0', '\u2028', '\u2029', '\ufeff',
+			),
+			// space separators except u+0020 and u+00a0
+			RuneSet('\u1680', '\u202f', '\u205f', '\u3000'),
+			RuneRange('\u2000', '\u200a'),
+			Named("LineComment"),
+			Named("BlockComment"),
+		),
+	)
+}
+
+func (j JSON5Parser) Member() *Instruction {
+	return Seq(
+		Longest(
+			Named("String"),
+			Seq(
+				Named("Blank"),
+				Named("Identifier"),
+			),
+		),
+		j.Lexical(":"),
+		Named("Value"),
+	)
+}
+
+func (_ JSON5Parser) Identifier() *Instruction {
+	return Seq(
+		Named("IdentifierStart"),
+		ZeroOrMore(
+			Longest(
+				Named("IdentifierStart"),
+				RuneCategory("Mn"),
+				RuneCategory("Mc"),
+				RuneCategory("Nd"),
+				// connector punctuations except '_', zwnj, zwj
+				RuneSet(
+					'\u203f', '\u2040', '\u2054', '\ufe33', '\ufe34',
+					'\ufe4d', '\ufe4e', '\ufe4f', '\uff3f',
+					'\u200c', '\u200d',
+				),
+			),
+		),
+	)
+}
+
+func (_ JSON5Parser) IdentifierStart() *Instruction {
+	return Longest(
+		RuneCategory("L"),
+		RuneCategory("Nl"),
+		RuneSet('$', '_'),
+		Seq(
+			Literal(`\u`),
+			Named("HexDigit"),
+			Named("HexDigit"),
+			Named("HexDigit"),
+			Named("HexDigit"),
+		),
+	)
+}
+
+func (j JSON5Parser) String() *Instruction {
+	return Longest(
+		Seq(
+			j.Lexical(`"`),
+			ZeroOrMore(
+				Longest(
+					RuneInverse(RuneSet('"', '\\', '\n', '\r')),
+					Named("Escape"),
+				),
+			),
+			Literal(`"`),
+		),
+		Seq(
+			j.Lexical(`'`),
+			ZeroOrMore(
+				Longest(
+					RuneInverse(RuneSet('\'', '\\', '\n', '\r')),
+					Named("Escape"),
+				),
+			),
+			Literal(`'`),
+		),
+	)
+}
+
+func (_ JSON5Parser) Escape() *Instruction {
+	return Seq(
+		Rune('\\'),
+		Longest(
+			RuneInverse(RuneSet(
+				'x', 'u', '\n', '\r',
+				'1', '2', '3', '4', '5', '6', '7', '8', '9',
+			)),
+			// line continuation
+			Rune('\n'),
+			Rune('\r'),
+			Literal("\r\n"),
+			Seq(
+				Rune('x'),
+				Named("HexDigit"),
+				Named("HexDigit"),
+			),
+			Seq(
+				Rune('u'),
+				Named("HexDigit"),
+				Named("HexDigit"),
+				Named("HexDigit"),
+				Named("HexDigit"),
+			),
+		),
+	)
+}
+
+func (_ JSON5Parser) Number() *Instruction {
+	return Seq(
+		Optional(
+			RuneSet('+', '-'),
+		),
+		Longest(
+			Literal("Infinity"),
+			Literal("NaN"),
+			Seq(
+				Rune('0'),
+				RuneSet('x', 'X'),
+				OneOrMore(
+					Named("HexDigit"),
+				),
+			),
+			Seq(
+				Longest(
+					Seq(
+						Longest(
+							Rune('0'),
+							Seq(
+								RuneRange('1', '9'),
+								ZeroOrMore(
+									RuneRange('0', '9'),
+								),
+							),
+						),
+						Optional(
+							Seq(
+								Rune('.'),
+								ZeroOrMore(
+									RuneRange('0', '9'),
+								),
+							),
+						),
+					),
+					Seq(
+						Rune('.'),
+						OneOrMore(
+							RuneRange('0', '9'),
+						),
+					),
+				),
+				Optional(
+					Seq(
+						RuneSet('e', 'E'),
+						Optional(
+							RuneSet('+', '-'),
+						),
+						OneOrMore(
+							RuneRange('0', '9'),
+						),
+					),
+				),
+			),
+		),
+	)
+}
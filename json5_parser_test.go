@@ -0,0 +1,69 @@
+package pav
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSON5(t *testing.T) {
+	for _, input := range []string{
+		`{
+			// comments
+			unquoted: 'and you can quote me on that',
+			singleQuotes: 'I can use "double quotes" here',
+			lineBreaks: "Look, Mom! \
+No \\n's!",
+			hexadecimal: 0xdecaf,
+			leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+			positiveSign: +1,
+			trailingComma: 'in objects', andIn: ['arrays',],
+			"backwardsCompatible": "with JSON",
+		}`,
+		`[Infinity, -Infinity, NaN, +NaN, 0X1F, 1e+5, .5e-1]`,
+		`{$_a1: 1, _: 2, ünïcödé: 3, ab: 4}`,
+		"{'\\x41\\u0041\\'\\0': null}",
+		"\v\f [] ",
+		`'' /* trailing */`,
+	} {
+		vm := NewVMFromObject(new(JSON5Parser), Named("Text"))
+		if !match(vm, input) {
+			t.Errorf("should match: %q", input)
+		}
+	}
+
+	for _, input := range []string{
+		`{1a: 1}`,
+		`{a b: 1}`,
+		`[0x]`,
+		`[01]`,
+		`[.]`,
+		`[Inf]`,
+		`['a\x4']`,
+		`['a\1']`,
+		"['a\nb']",
+		`'a"`,
+	} {
+		vm := NewVMFromObject(new(JSON5Parser), Named("Text"))
+		if match(vm, input) {
+			t.Errorf("should not match: %q", input)
+		}
+	}
+}
+
+func TestJSON5AcceptsJSON(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "json", "y_*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vm := NewVMFromObject(new(JSON5Parser), Named("Text"))
+		if !match(vm, string(content)) {
+			t.Errorf("should match: %s", path)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package pav
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+type JSONEventType uint8
+
+const (
+	JSONStartObject JSONEventType = iota + 1
+	JSONEndObject
+	JSONStartArray
+	JSONEndArray
+	JSONKey
+	JSONString
+	JSONNumber
+	JSONBool
+	JSONNull
+)
+
+type JSONEvent struct {
+	Type JSONEventType
+	// decoded string for JSONKey and JSONString, source text for other scalars
+	Value string
+	// byte offset of the token
+	Offset int64
+}
+
+// JSONDecoder validates the input with JSONParser and emits events as soon as the tokens are complete.
+// Memory usage depends on nesting depth and token length only.
+//
+// Events are not derived from captures, which would keep the whole input until the end.
+// A small tokenizer is fed with runes only after the VM accepts them, so it sees prefixes of valid JSON only
+// and relies on JSONParser for all validation. Its events must agree with the captures of JSONParser,
+// changes to either have to keep TestJSONDecoderGrammar passing.
+type JSONDecoder struct {
+	r       *bufio.Reader
+	vm      *VM
+	offset  int64
+	matched bool
+	err     error
+
+	events []JSONEvent
+	next   int
+
+	objects   []bool
+	expectKey bool
+
+	token      []byte
+	tokenType  JSONEventType
+	tokenStart int64
+	inString   bool
+	escaped    bool
+}
+
+func NewJSONDecoder(r io.Reader) *JSONDecoder {
+	return &JSONDecoder{
+		r:  bufio.NewReader(r),
+		vm: NewVMFromObject(new(JSONParser), Named("Text")),
+	}
+}
+
+// Next returns the next event, or io.EOF after the last one.
+// Invalid UTF-8 is reported as *OffsetError wrapping ErrInvalidUTF8.
+func (d *JSONDecoder) Next() (JSONEvent, error) {
+	for {
+
+		if d.next < len(d.events) {
+			ev := d.events[d.next]
+			d.next++
+			return ev, nil
+		}
+		d.events = d.events[:0]
+		d.next = 0
+
+		if d.err != nil {
+			return JSONEvent{}, d.err
+		}
+
+		r, size, err := d.r.ReadRune()
+		if err == io.EOF {
+			if !d.matched {
+				d.err = fmt.Errorf("unexpected end of input at offset %d", d.offset)
+				continue
+			}
+			d.flush()
+			d.err = io.EOF
+			continue
+		} else if err != nil {
+			d.err = err
+			continue
+		}
+		if r == utf8.RuneError && size == 1 {
+			d.err = &OffsetError{
+				Offset: d.offset,
+				Err:    ErrInvalidUTF8,
+			}
+			continue
+		}
+
+		res := d.vm.Step(r)
+		d.matched = len(res.Matched) > 0
+		if !d.matched && len(d.vm.Threads) == 0 {
+			d.err = fmt.Errorf("syntax error at offset %d", d.offset)
+			continue
+		}
+		d.feed(r)
+		d.offset += int64(size)
+
+	}
+}
+
+func (d *JSONDecoder) feed(r rune) {
+
+	if d.inString {
+		d.token = appendRune(d.token, r)
+		if d.escaped {
+			d.escaped = false
+		} else if r == '\\' {
+			d.escaped = true
+		} else if r == '"' {
+			d.inString = false
+			var s string
+			if err := json.Unmarshal(d.token, &s); err != nil { // NOCOVER
+				panic(err)
+			}
+			d.emit(d.tokenType, s, d.tokenStart)
+			d.token = d.token[:0]
+		}
+		return
+	}
+
+	if len(d.token) > 0 {
+		if isJSONBareRune(r) {
+			d.token = appendRune(d.token, r)
+			return
+		}
+		d.flush()
+	}
+
+	switch r {
+
+	case '{':
+		d.emit(JSONStartObject, "", d.offset)
+		d.objects = append(d.objects, true)
+		d.expectKey = true
+
+	case '[':
+		d.emit(JSONStartArray, "", d.offset)
+		d.objects = append(d.objects, false)
+
+	case '}':
+		d.emit(JSONEndObject, "", d.offset)
+		d.objects = d.objects[:len(d.objects)-1]
+
+	case ']':
+		d.emit(JSONEndArray, "", d.offset)
+		d.objects = d.objects[:len(d.objects)-1]
+
+	case ',':
+		d.expectKey = d.inObject()
+
+	case ':':
+		d.expectKey = false
+
+	case '"':
+		d.inString = true
+		d.tokenType = JSONString
+		if d.inObject() && d.expectKey {
+			d.tokenType = JSONKey
+		}
+		d.tokenStart = d.offset
+		d.token = append(d.token, '"')
+
+	case 't', 'f':
+		d.startBare(JSONBool, r)
+
+	case 'n':
+		d.startBare(JSONNull, r)
+
+	default:
+		if r == '-' || r >= '0' && r <= '9' {
+			d.startBare(JSONNumber, r)
+		}
+
+	}
+}
+
+func (d *JSONDecoder) inObject() bool {
+	return len(d.objects) > 0 && d.objects[len(d.objects)-1]
+}
+
+func (d *JSONDecoder) startBare(t JSONEventType, r rune) {
+	d.tokenType = t
+	d.tokenStart = d.offset
+	d.token = appendRune(d.token, r)
+}
+
+func (d *JSONDecoder) flush() {
+	if len(d.token) == 0 {
+		return
+	}
+	d.emit(d.tokenType, string(d.token), d.tokenStart)
+	d.token = d.token[:0]
+}
+
+func (d *JSONDecoder) emit(t JSONEventType, value string, offset int64) {
+	d.events = append(d.events, JSONEvent{
+		Type:   t,
+		Value:  value,
+		Offset: offset,
+	})
+}
+
+func isJSONBareRune(r rune) bool {
+	return r >= '0' && r <= '9' ||
+		r >= 'a' && r <= 'z' ||
+		r == '.' || r == '+' || r == '-' || r == 'E'
+}
+
+func appendRune(bs []byte, r rune) []byte {
+	if r < 0x80 {
+		return append(bs, byte(r))
+	}
+	return append(bs, string(r)...)
+}
@@ -0,0 +1,235 @@
+package pav
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func decodeJSONEvents(t *testing.T, input string) ([]string, error) {
+	t.Helper()
+	d := NewJSONDecoder(strings.NewReader(input))
+	var events []string
+	for {
+		ev, err := d.Next()
+		if err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, ev.Type.String()+" "+ev.Value)
+	}
+}
+
+func TestJSONDecoder(t *testing.T) {
+	events, err := decodeJSONEvents(t, ` {"a": [1, "b\"c", true, null], "d": {"e": -1.5e3}, "f": false} `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		events, []string{
+			"JSONStartObject ",
+			"JSONKey a",
+			"JSONStartArray ",
+			"JSONNumber 1",
+			`JSONString b"c`,
+			"JSONBool true",
+			"JSONNull null",
+			"JSONEndArray ",
+			"JSONKey d",
+			"JSONStartObject ",
+			"JSONKey e",
+			"JSONNumber -1.5e3",
+			"JSONEndObject ",
+			"JSONKey f",
+			"JSONBool false",
+			"JSONEndObject ",
+		},
+	)
+
+	events, err = decodeJSONEvents(t, `42`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		events, []string{"JSONNumber 42"},
+	)
+}
+
+func TestJSONDecoderIncremental(t *testing.T) {
+	r, w := io.Pipe()
+	d := NewJSONDecoder(r)
+	go func() {
+		w.Write([]byte(`[{"a": "b"}, `))
+	}()
+	for _, expected := range []JSONEventType{
+		JSONStartArray,
+		JSONStartObject,
+		JSONKey,
+		JSONString,
+		JSONEndObject,
+	} {
+		ev, err := d.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t,
+			ev.Type, expected,
+		)
+	}
+	w.Close()
+	_, err := d.Next()
+	if err == nil || err == io.EOF {
+		t.Fatal("should fail")
+	}
+}
+
+func TestJSONDecoderError(t *testing.T) {
+	events, err := decodeJSONEvents(t, `[1, x]`)
+	if err == nil {
+		t.Fatal("should fail")
+	}
+	eq(t,
+		err.Error(), "syntax error at offset 4",
+		events, []string{
+			"JSONStartArray ",
+			"JSONNumber 1",
+		},
+	)
+
+	_, err = decodeJSONEvents(t, `{"a": 1`)
+	if err == nil {
+		t.Fatal("should fail")
+	}
+
+	events, err = decodeJSONEvents(t, "[1, \"a\xff\"]")
+	eq(t,
+		errors.Is(err, ErrInvalidUTF8), true,
+		err.Error(), "at offset 6: invalid UTF-8",
+		events, []string{
+			"JSONStartArray ",
+			"JSONNumber 1",
+		},
+	)
+}
+
+func TestJSONDecoderGrammar(t *testing.T) {
+	// events of the tokenizer agree with the captures of JSONParser
+	paths, err := filepath.Glob(filepath.Join("testdata", "json", "y_*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test cases")
+	}
+	inputs := []string{
+		` {"a": [1, "b\"c", true, null], "d": {"e": -1.5e3}, "f": false} `,
+		`[{"a": {"b": ["c", {}]}}, [], [[0]], "\u00e9\n"]`,
+		"{\"a\":1,\"b\":[true,false],\"c\":\"{}[],:\"}",
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(content))
+	}
+
+	for _, input := range inputs {
+		events, err := decodeJSONEvents(t, input)
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		vm := NewVMFromObject(new(JSONParser), Named("Text"))
+		vm.Capture = true
+		var res StepResult
+		runes := []rune(input)
+		for _, r := range runes {
+			res = vm.Step(r)
+		}
+		if len(res.Matched) == 0 {
+			t.Fatalf("%q: not matched", input)
+		}
+		var expected []string
+		captureJSONEvents(runes, res.Matched[0].Tree().Find("Text").Find("Value"), &expected)
+		eq(t,
+			events, expected,
+		)
+	}
+}
+
+// captureJSONEvents appends events of the Value node in the format of decodeJSONEvents
+func captureJSONEvents(runes []rune, value *Node, events *[]string) {
+	text := func(n *Node) string {
+		return strings.TrimLeft(string(runes[n.Start:n.End]), " \t\r\n")
+	}
+	str := func(n *Node) string {
+		var s string
+		if err := json.Unmarshal([]byte(text(n)), &s); err != nil {
+			panic(err)
+		}
+		return s
+	}
+	var node *Node
+	for _, child := range value.Children {
+		if child.Name != "Blank" {
+			node = child
+			break
+		}
+	}
+	if node == nil {
+		// literals
+		switch text(value) {
+		case "null":
+			*events = append(*events, "JSONNull null")
+		default:
+			*events = append(*events, "JSONBool "+text(value))
+		}
+		return
+	}
+	switch node.Name {
+	case "String":
+		*events = append(*events, "JSONString "+str(node))
+	case "Number":
+		*events = append(*events, "JSONNumber "+text(node))
+	case "Object":
+		*events = append(*events, "JSONStartObject ")
+		for _, child := range node.Children {
+			switch child.Name {
+			case "String":
+				*events = append(*events, "JSONKey "+str(child))
+			case "Value":
+				captureJSONEvents(runes, child, events)
+			}
+		}
+		*events = append(*events, "JSONEndObject ")
+	case "Array":
+		*events = append(*events, "JSONStartArray ")
+		for _, child := range node.Children {
+			if child.Name == "Value" {
+				captureJSONEvents(runes, child, events)
+			}
+		}
+		*events = append(*events, "JSONEndArray ")
+	}
+}
+
+func BenchmarkJSONDecoder(b *testing.B) {
+	input := "[" + strings.Repeat(`{"foo": [1, 2.5, "bar"]}, `, 1024) + "null]"
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		d := NewJSONDecoder(strings.NewReader(input))
+		for {
+			_, err := d.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package pav
 
+import "unicode"
+
 type JSONParser struct{}
 
 func (_ JSONParser) Lexical(str string) *Instruction {
@@ -11,7 +13,14 @@ func (_ JSONParser) Lexical(str string) *Instruction {
 
 func (_ JSONParser) Blank() *Instruction {
 	return ZeroOrMore(
-		RuneSet(' ', '\b', '\f', '\n', '\r', '\t'),
+		RuneSet(' ', '\n', '\r', '\t'),
+	)
+}
+
+func (_ JSONParser) Text() *Instruction {
+	return Seq(
+		Named("Value"),
+		Named("Blank"),
 	)
 }
 
@@ -20,7 +29,6 @@ func (j JSONParser) Object() *Instruction {
 		j.Lexical("{"),
 		Optional(
 			Seq(
-				Named("Blank"),
 				Named("String"),
 				j.Lexical(":"),
 				Named("Value"),
@@ -57,17 +65,18 @@ func (j JSONParser) Array() *Instruction {
 }
 
 func (j JSONParser) Value() *Instruction {
-	return Seq(
-		Named("Blank"),
-		Longest(
-			Named("String"),
+	// String, Object, Array and the literals begin with Blank, a leading Blank here would make blanks ambiguous
+	return Longest(
+		Named("String"),
+		Seq(
+			Named("Blank"),
 			Named("Number"),
-			Named("Object"),
-			Named("Array"),
-			j.Lexical("true"),
-			j.Lexical("false"),
-			j.Lexical("null"),
 		),
+		Named("Object"),
+		Named("Array"),
+		j.Lexical("true"),
+		j.Lexical("false"),
+		j.Lexical("null"),
 	)
 }
 
@@ -76,9 +85,10 @@ func (j JSONParser) String() *Instruction {
 		j.Lexical(`"`),
 		ZeroOrMore(
 			Longest(
-				RuneInverse(
-					RuneSet('"', '\\'),
-				),
+				// unescaped
+				RuneRange(0x20, 0x21),
+				RuneRange(0x23, 0x5b),
+				RuneRange(0x5d, unicode.MaxRune),
 				Literal(`\"`),
 				Literal(`\\`),
 				Literal(`\/`),
@@ -89,10 +99,10 @@ func (j JSONParser) String() *Instruction {
 				Literal(`\t`),
 				Seq(
 					Literal(`\u`),
-					RuneRange('0', '9'),
-					RuneRange('0', '9'),
-					RuneRange('0', '9'),
-					RuneRange('0', '9'),
+					Named("HexDigit"),
+					Named("HexDigit"),
+					Named("HexDigit"),
+					Named("HexDigit"),
 				),
 			),
 		),
@@ -100,13 +110,13 @@ func (j JSONParser) String() *Instruction {
 	)
 }
 
-func (j JSONParser) Number() *Instruction {
+func (_ JSONParser) Number() *Instruction {
 	return Seq(
 		Optional(
-			j.Lexical("-"),
+			Literal("-"),
 		),
 		Longest(
-			j.Lexical("0"),
+			Literal("0"),
 			Seq(
 				RuneRange('1', '9'),
 				ZeroOrMore(
@@ -116,7 +126,7 @@ func (j JSONParser) Number() *Instruction {
 		),
 		Optional(
 			Seq(
-				j.Lexical("."),
+				Literal("."),
 				OneOrMore(
 					RuneRange('0', '9'),
 				),
@@ -125,13 +135,13 @@ func (j JSONParser) Number() *Instruction {
 		Optional(
 			Seq(
 				Longest(
-					j.Lexical("e"),
-					j.Lexical("E"),
+					Literal("e"),
+					Literal("E"),
 				),
 				Optional(
 					Longest(
-						j.Lexical("+"),
-						j.Lexical("-"),
+						Literal("+"),
+						Literal("-"),
 					),
 				),
 				OneOrMore(
@@ -141,3 +151,11 @@ func (j JSONParser) Number() *Instruction {
 		),
 	)
 }
+
+func (_ JSONParser) HexDigit() *Instruction {
+	return First(
+		RuneRange('0', '9'),
+		RuneRange('a', 'f'),
+		RuneRange('A', 'F'),
+	)
+}
@@ -1,7 +1,12 @@
 package pav
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestJSONEmptyObject(t *testing.T) {
@@ -48,6 +53,7 @@ func TestJSONString(t *testing.T) {
 		`"\n\""`,
 		`"\n\"\\"`,
 		`"\n\"\\\u1234"`,
+		`"\u00e9\uABCD"`,
 	} {
 		vm := NewVMFromObject(new(JSONParser), &Instruction{
 			Op:   OpCall,
@@ -140,3 +146,92 @@ func TestJSONObject(t *testing.T) {
 		)
 	}
 }
+
+func matchJSON(input []byte) bool {
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	return match(vm, string(input))
+}
+
+func TestJSONConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "json", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test cases")
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok := matchJSON(content)
+		switch name[0] {
+		case 'y':
+			if !ok {
+				t.Errorf("%s: should accept", name)
+			}
+		case 'n':
+			if ok {
+				t.Errorf("%s: should reject", name)
+			}
+		}
+	}
+}
+
+func TestJSONDifferential(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "json", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []string{
+		`{"a": [1, 2.5, -3e4, "é", {"b": null}]}`,
+		"\t[ true , false ]\r\n",
+		`"éꯍ"`,
+		`"\u00g0"`,
+		`[1,]`,
+		`{"a" 1}`,
+		`01`,
+		`1.`,
+		`-`,
+		"\"\x1f\"",
+		"\"\x7f\"",
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// runes are fed to the vm, so invalid encodings are not comparable
+		if !utf8.Valid(content) {
+			continue
+		}
+		inputs = append(inputs, string(content))
+	}
+	for _, input := range inputs {
+		if ok, expected := matchJSON([]byte(input)), json.Valid([]byte(input)); ok != expected {
+			t.Errorf("%q: got %v, encoding/json got %v", input, ok, expected)
+		}
+	}
+}
+
+func TestJSONBlankThreads(t *testing.T) {
+	// blanks before a value are matched by one Blank only.
+	// With a Blank before every Value in addition to those of the lexical tokens,
+	// each blank before a value doubles the threads, and one element of the input spawns hundreds.
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	input := " [" + strings.Repeat(` { "a" : [ 1 , true , "b" ] } ,`, 100) + " null ] "
+	max := 0
+	var res StepResult
+	for _, r := range input {
+		res = vm.Step(r)
+		if len(vm.Threads) > max {
+			max = len(vm.Threads)
+		}
+	}
+	eq(t,
+		len(res.Matched) > 0, true,
+		max < 32, true,
+	)
+}
@@ -0,0 +1,117 @@
+package pav
+
+// JSONCParser accepts JSON with comments and trailing commas
+type JSONCParser struct {
+	JSONParser
+}
+
+func (_ JSONCParser) Blank() *Instruction {
+	return ZeroOrMore(
+		Longest(
+			RuneSet(' ', '\n', '\r', '\t'),
+			Named("LineComment"),
+			Named("BlockComment"),
+		),
+	)
+}
+
+func (_ JSONCParser) Text() *Instruction {
+	return Seq(
+		Named("Value"),
+		Named("Blank"),
+		// line comment at the end of input
+		Optional(
+			Seq(
+				Literal("//"),
+				ZeroOrMore(
+					RuneInverse(Rune('\n')),
+				),
+			),
+		),
+	)
+}
+
+func (_ JSONCParser) LineComment() *Instruction {
+	return Seq(
+		Literal("//"),
+		ZeroOrMore(
+			RuneInverse(Rune('\n')),
+		),
+		Rune('\n'),
+	)
+}
+
+func (_ JSONCParser) BlockComment() *Instruction {
+	// /\*[^*]*\*+([^/*][^*]*\*+)*/
+	return Seq(
+		Literal("/*"),
+		ZeroOrMore(
+			RuneInverse(Rune('*')),
+		),
+		OneOrMore(
+			Rune('*'),
+		),
+		ZeroOrMore(
+			Seq(
+				RuneInverse(RuneSet('/', '*')),
+				ZeroOrMore(
+					RuneInverse(Rune('*')),
+				),
+				OneOrMore(
+					Rune('*'),
+				),
+			),
+		),
+		Rune('/'),
+	)
+}
+
+func (j JSONCParser) Object() *Instruction {
+	return Seq(
+		j.Lexical("{"),
+		Optional(
+			Seq(
+				Named("Member"),
+				ZeroOrMore(
+					Seq(
+						j.Lexical(","),
+						Named("Member"),
+					),
+				),
+				Optional(
+					j.Lexical(","),
+				),
+			),
+		),
+		j.Lexical("}"),
+	)
+}
+
+func (j JSONCParser) Member() *Instruction {
+	return Seq(
+		Named("String"),
+		j.Lexical(":"),
+		Named("Value"),
+	)
+}
+
+func (j JSONCParser) Array() *Instruction {
+	return Seq(
+		j.Lexical("["),
+		Optional(
+			Seq(
+				Named("Value"),
+				ZeroOrMore(
+					Seq(
+						j.Lexical(","),
+						Named("Value"),
+					),
+				),
+				Optional(
+					j.Lexical(","),
+				),
+			),
+		),
+		j.Lexical("]"),
+	)
+}
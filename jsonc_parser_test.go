@@ -0,0 +1,65 @@
+package pav
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONC(t *testing.T) {
+	for _, input := range []string{
+		`{}`,
+		`// comment
+		{"a": 1}`,
+		`{"a": 1} // comment`,
+		`{"a": 1} // comment
+		`,
+		`{
+			// comment
+			"a": /* comment */ 1,
+			"b": [1, 2, 3,], /**/
+			"c": /* * / ** */ "//",
+		}`,
+		`[1, /* , */ 2,]`,
+		`/*/ */ 1`,
+	} {
+		vm := NewVMFromObject(new(JSONCParser), Named("Text"))
+		if !match(vm, input) {
+			t.Errorf("should match: %q", input)
+		}
+	}
+
+	for _, input := range []string{
+		`{,}`,
+		`[,]`,
+		`[1,,]`,
+		`[1 //, 2
+		3]`,
+		`[1 /* */ */ 2]`,
+		`{"a": 1 /* comment }`,
+		`/ 1`,
+		`{a: 1}`,
+	} {
+		vm := NewVMFromObject(new(JSONCParser), Named("Text"))
+		if match(vm, input) {
+			t.Errorf("should not match: %q", input)
+		}
+	}
+}
+
+func TestJSONCAcceptsJSON(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "json", "y_*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vm := NewVMFromObject(new(JSONCParser), Named("Text"))
+		if !match(vm, string(content)) {
+			t.Errorf("should match: %s", path)
+		}
+	}
+}
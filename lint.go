@@ -0,0 +1,254 @@
+package pav
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Lint reports the findings of Analyze, alternatives of First and Shortest shadowed by earlier ones,
+// and routines not reachable from the start rules if any.
+// An alternative is shadowed if an earlier one matches a prefix of every input of it:
+// fixed rune sequences are compared rune by rune, and an alternative that can not match the empty input
+// is shadowed if the earlier one may return after any rune the alternative may begin with,
+// like [a-z]+ before "if".
+func Lint(routines map[string]Routine, starts ...string) []Finding {
+	return lint(routines, nil, starts)
+}
+
+// LintObject lints routines of obj, also reporting calls by name to methods of obj with parameters
+func LintObject(obj interface{}, starts ...string) []Finding {
+	return lint(ObjectRoutines(obj), reflect.TypeOf(obj), starts)
+}
+
+func lint(routines map[string]Routine, objType reflect.Type, starts []string) []Finding {
+	a := Analyze(routines)
+	var findings []Finding
+	for _, f := range a.Findings {
+		if f.Kind == UndefinedName && objType != nil {
+			if _, ok := objType.MethodByName(f.Name); ok {
+				f.Kind = HelperReference
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	// shadowed alternatives
+	for _, node := range a.nodes {
+		inst := node.inst
+		if inst.Op != OpClone || inst.ClusterType != ClusterShortest {
+			continue
+		}
+		var seqs [][]*Instruction
+		var singles []*RuneTable
+		for _, branch := range inst.Insts {
+			seqs = append(seqs, runeTestSeq(branch, routines, nil))
+			singles = append(singles, singleRuneTable(a, branch))
+		}
+	loop_branch:
+		for j := range inst.Insts {
+			for i := 0; i < j; i++ {
+				if shadows(seqs[i], seqs[j]) || shadowsFirst(a, singles[i], inst.Insts[j]) {
+					findings = append(findings, Finding{
+						Kind:        ShadowedAlternative,
+						Rule:        a.owners[inst],
+						Inst:        inst.Insts[j],
+						Alternative: j,
+						ShadowedBy:  i,
+					})
+					continue loop_branch
+				}
+			}
+		}
+	}
+
+	// unreachable rules
+	if len(starts) > 0 {
+		reachable := make(map[string]bool)
+		visited := make(map[*Instruction]bool)
+		var names []string
+		reach := func(name string) {
+			if !reachable[name] {
+				reachable[name] = true
+				names = append(names, name)
+			}
+		}
+		for _, name := range starts {
+			reach(name)
+		}
+		for i := 0; i < len(names); i++ {
+			walkInstructions(routines[names[i]].Start, visited, func(inst *Instruction) bool {
+				if inst.Op == OpCall && inst.Inst == nil && inst.Name != "" {
+					reach(inst.Name)
+				}
+				return true
+			})
+		}
+		for name, r := range routines {
+			if !reachable[name] {
+				findings = append(findings, Finding{
+					Kind: UnreachableRule,
+					Rule: name,
+					Inst: r.Start,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Rule != findings[j].Rule {
+			return findings[i].Rule < findings[j].Rule
+		}
+		return findings[i].Kind < findings[j].Kind
+	})
+	return findings
+}
+
+// runeTestSeq returns the rune instructions of the instruction if it matches a fixed sequence of runes, or nil
+func runeTestSeq(inst *Instruction, routines map[string]Routine, calling map[string]bool) []*Instruction {
+	var seq []*Instruction
+	for ; inst != nil; inst = inst.Next {
+		switch inst.Op {
+		case OpRune:
+			if inst.Predict {
+				return nil
+			}
+			seq = append(seq, inst)
+		case OpCall:
+			callee := inst.Inst
+			if callee == nil {
+				r, ok := routines[inst.Name]
+				if !ok || calling[inst.Name] {
+					return nil
+				}
+				if calling == nil {
+					calling = make(map[string]bool)
+				}
+				calling[inst.Name] = true
+				sub := runeTestSeq(r.Start, routines, calling)
+				delete(calling, inst.Name)
+				if sub == nil {
+					return nil
+				}
+				seq = append(seq, sub...)
+				continue
+			}
+			sub := runeTestSeq(callee, routines, calling)
+			if sub == nil {
+				return nil
+			}
+			seq = append(seq, sub...)
+		default:
+			return nil
+		}
+	}
+	return seq
+}
+
+// shadows reports whether every rune sequence matched by b has a prefix matched by a
+func shadows(a, b []*Instruction) bool {
+	if len(a) == 0 || len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if !covers(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// covers reports whether every rune matched by b is matched by a
+func covers(a, b *Instruction) bool {
+	return runeTableOf(a).containsTable(runeTableOf(b))
+}
+
+// singleRuneTable returns the runes after which the instruction may return, if it consumes only one, or nil if none
+func singleRuneTable(a *Analysis, inst *Instruction) *RuneTable {
+	type frame struct {
+		ret    *Instruction
+		callee *Instruction
+	}
+	type key struct {
+		inst  *Instruction
+		depth int
+	}
+	var ranges [][2]rune
+	visited := make(map[key]bool)
+	var walk func(inst *Instruction, frames []frame)
+	call := func(callee *Instruction, ret *Instruction, frames []frame) {
+		for _, f := range frames {
+			if f.callee == callee {
+				// left recursion
+				return
+			}
+		}
+		walk(callee, append(frames[:len(frames):len(frames)], frame{
+			ret:    ret,
+			callee: callee,
+		}))
+	}
+	walk = func(inst *Instruction, frames []frame) {
+		if inst == nil || inst.Op == OpReturn {
+			if n := len(frames); n > 0 {
+				walk(frames[n-1].ret, frames[:n-1])
+			}
+			return
+		}
+		k := key{inst, len(frames)}
+		if visited[k] {
+			return
+		}
+		visited[k] = true
+		switch inst.Op {
+		case OpRune:
+			if inst.Predict {
+				return
+			}
+			if !a.Nullable(inst.Next) {
+				return
+			}
+			for _, f := range frames {
+				if !a.Nullable(f.ret) {
+					return
+				}
+			}
+			ranges = append(ranges, runeTableOf(inst).Ranges...)
+		case OpCall:
+			if inst.Inst != nil {
+				call(inst.Inst, inst.Next, frames)
+			} else if r, ok := a.Routines[inst.Name]; ok {
+				call(r.Start, inst.Next, frames)
+			}
+		case OpJump:
+			walk(inst.Inst, frames)
+		case OpClone:
+			for _, branch := range inst.Insts {
+				call(branch, inst.Next, frames)
+			}
+		case OpIndirect:
+			walk(*inst.InstP, frames)
+		}
+	}
+	walk(inst, nil)
+	if len(ranges) == 0 {
+		return nil
+	}
+	return NewRuneTable(ranges)
+}
+
+// shadowsFirst reports whether an alternative returning after any rune of singles finishes no later than b
+func shadowsFirst(a *Analysis, singles *RuneTable, b *Instruction) bool {
+	if singles == nil || a.Nullable(b) {
+		return false
+	}
+	first := a.First(b)
+	if len(first) == 0 {
+		return false
+	}
+	for _, inst := range first {
+		if !singles.containsTable(runeTableOf(inst)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package pav
+
+import (
+	"fmt"
+	"testing"
+)
+
+type lintGrammar struct{}
+
+func (_ lintGrammar) Start() *Instruction {
+	return Seq(Named("Keyword"), Named("Token"), Named("Lexical"))
+}
+
+func (_ lintGrammar) Keyword() *Instruction {
+	return First(
+		Literal("in"),
+		Literal("int"),
+		Named("If"),
+		RuneRange('a', 'z'),
+		Rune('q'),
+	)
+}
+
+func (_ lintGrammar) If() *Instruction {
+	return Literal("if")
+}
+
+func (_ lintGrammar) Token() *Instruction {
+	return Rune('t')
+}
+
+func (_ lintGrammar) Unused() *Instruction {
+	return Literal("x")
+}
+
+func (_ lintGrammar) Lexical(s string) *Instruction {
+	return Literal(s)
+}
+
+func TestLint(t *testing.T) {
+	lint := func(findings []Finding) string {
+		var ss []string
+		for _, f := range findings {
+			ss = append(ss, f.String())
+		}
+		return fmt.Sprintf("%q", ss)
+	}
+	eq(t,
+		lint(LintObject(lintGrammar{}, "Start")),
+		fmt.Sprintf("%q", []string{
+			"ShadowedAlternative in Keyword: 1 by 0 at lint_test.go:17",
+			"ShadowedAlternative in Keyword: 4 by 3 at lint_test.go:20",
+			"HelperReference in Start: Lexical at lint_test.go:11",
+			"UnreachableRule in Unused at lint_test.go:33",
+		}),
+		lint(Lint(ObjectRoutines(lintGrammar{}))),
+		fmt.Sprintf("%q", []string{
+			"ShadowedAlternative in Keyword: 1 by 0 at lint_test.go:17",
+			"ShadowedAlternative in Keyword: 4 by 3 at lint_test.go:20",
+			"UndefinedName in Start: Lexical at lint_test.go:11",
+		}),
+	)
+
+	// shadowed
+	vm := NewVMFromObject(lintGrammar{}, Named("Keyword"))
+	eq(t,
+		match(vm, "int"), false,
+	)
+}
+
+func TestLintShadowedFirst(t *testing.T) {
+	for _, c := range []struct {
+		inst     *Instruction
+		shadowed []int
+	}{
+		// classes
+		{First(RuneRange('a', 'z'), Literal("if")), []int{1}},
+		{First(RuneSet('i', 'j'), Literal("if"), Literal("jf"), Literal("kf")), []int{1, 2}},
+		// repetitions
+		{First(OneOrMore(RuneRange('a', 'z')), Literal("if"), Named("A")), []int{1, 2}},
+		{First(Seq(Rune('a'), ZeroOrMore(Rune('b'))), Seq(Rune('a'), Rune('c'))), []int{1}},
+		// not shadowed
+		{First(Literal("if"), RuneRange('a', 'z')), nil},
+		{First(OneOrMore(Literal("ab")), Literal("ac")), nil},
+		{First(Rune('a'), Optional(Rune('a'))), nil},
+		{First(Seq(Rune('a'), Rune('b')), Rune('a')), nil},
+		{First(Seq(Optional(Rune('a')), Rune('b')), Literal("ab")), nil},
+	} {
+		findings := Lint(map[string]Routine{
+			"A":     {Start: Literal("xy")},
+			"Start": {Start: Seq(c.inst, Named("A"))},
+		})
+		var shadowed []int
+		for _, f := range findings {
+			if f.Kind == ShadowedAlternative {
+				shadowed = append(shadowed, f.Alternative)
+			}
+		}
+		eq(t,
+			shadowed, c.shadowed,
+		)
+	}
+}
+
+func TestLintBuiltin(t *testing.T) {
+	for obj, start := range map[interface{}]string{
+		new(JSONParser):        "Text",
+		new(JSONCParser):       "Text",
+		new(JSON5Parser):       "Text",
+		new(TextGrammarParser): "Grammar",
+		new(GoLexer):           "Program",
+	} {
+		for _, f := range LintObject(obj, start) {
+			t.Errorf("%T: %s", obj, f)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	eq(t,
+		covers(AnyRune(), RuneCategory("L")), true,
+		covers(RuneCategory("L"), RuneCategory("L")), true,
+		covers(RuneCategory("L"), Rune('a')), true,
+		covers(RuneCategory("L"), RuneInverse(RuneCategory("L"))), false,
+		covers(RuneRange('a', 'z'), RuneSet('b', 'c')), true,
+		covers(RuneRange('a', 'z'), RuneRange('0', 'z')), false,
+		covers(RuneSet('a', 'b', 'c'), RuneRange('a', 'c')), true,
+		covers(RuneCategory("L"), RuneRange(0, 0x10000)), false,
+	)
+}
@@ -0,0 +1,135 @@
+package pav
+
+import (
+	"context"
+	"runtime"
+	"unicode/utf8"
+)
+
+// Result is the result of matching an input in MatchAll
+type Result struct {
+	// position of the input in the input channel, from 0
+	Index int
+	Input []byte
+	// whether the whole input is matched by the program
+	Match bool
+}
+
+// MatchAll is MatchAllN with GOMAXPROCS goroutines
+func MatchAll(ctx context.Context, program *Program, inputs <-chan []byte) <-chan Result {
+	return MatchAllN(ctx, program, inputs, runtime.GOMAXPROCS(0))
+}
+
+// MatchAllN matches inputs in the given number of goroutines, each running its own VM of the program.
+// Inputs are UTF-8 encoded, inputs with invalid encodings are not matched.
+// Results are sent in the order of inputs, the returned channel is closed after inputs is closed and all results are sent, or ctx is done.
+func MatchAllN(ctx context.Context, program *Program, inputs <-chan []byte, workers int) <-chan Result {
+	if workers < 1 {
+		workers = 1
+	}
+	type job struct {
+		index int
+		input []byte
+		done  chan Result
+	}
+	jobs := make(chan *job)
+	// jobs in input order
+	pending := make(chan *job, workers)
+	results := make(chan Result)
+
+	// dispatch
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for index := 0; ; index++ {
+			var input []byte
+			var ok bool
+			select {
+			case input, ok = <-inputs:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			j := &job{
+				index: index,
+				input: input,
+				done:  make(chan Result, 1),
+			}
+			select {
+			case pending <- j:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// match
+	for i := 0; i < workers; i++ {
+		go func() {
+			vm := program.NewVM()
+			for j := range jobs {
+				var match bool
+				if len(j.input) == 0 {
+					// VMs can not match empty input
+					match = vm.analysisOf(program.Start).Nullable(program.Start)
+				} else {
+					match = matchInput(vm, program.Start, j.input)
+				}
+				j.done <- Result{
+					Index: j.index,
+					Input: j.input,
+					Match: match,
+				}
+			}
+		}()
+	}
+
+	// collect
+	go func() {
+		defer close(results)
+		for j := range pending {
+			var result Result
+			select {
+			case result = <-j.done:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// matchInput reports whether the whole non-empty input is matched from the start instruction
+func matchInput(vm *VM, start *Instruction, input []byte) bool {
+	vm.Reset(start)
+	r, size := utf8.DecodeRune(input)
+	for {
+		if r == utf8.RuneError && size == 1 {
+			// invalid encoding
+			return false
+		}
+		input = input[size:]
+		next, nextSize := utf8.DecodeRune(input)
+		res := vm.StepPeek(r, next, len(input) > 0)
+		if len(input) == 0 {
+			return len(res.Matched) > 0
+		}
+		if len(vm.Threads) == 0 {
+			return false
+		}
+		r, size = next, nextSize
+	}
+}
@@ -0,0 +1,123 @@
+package pav
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestMatchAll(t *testing.T) {
+	program := NewProgramFromObject(new(JSONParser), Named("Text"))
+	var inputs [][]byte
+	var expected []bool
+	for i := 0; i < 200; i++ {
+		switch i % 4 {
+		case 0:
+			inputs = append(inputs, []byte(fmt.Sprintf(`{"n": %d}`, i)))
+			expected = append(expected, true)
+		case 1:
+			inputs = append(inputs, []byte(fmt.Sprintf(`[%d, "foo", [true]]`, i)))
+			expected = append(expected, true)
+		case 2:
+			inputs = append(inputs, []byte(fmt.Sprintf(`{"n": %d`, i)))
+			expected = append(expected, false)
+		case 3:
+			inputs = append(inputs, nil)
+			expected = append(expected, false)
+		}
+	}
+
+	for _, workers := range []int{0, 1, 4, 16} {
+		ch := make(chan []byte)
+		go func() {
+			for _, input := range inputs {
+				ch <- input
+			}
+			close(ch)
+		}()
+		var results []Result
+		if workers == 0 {
+			results = collectResults(MatchAll(context.Background(), program, ch))
+		} else {
+			results = collectResults(MatchAllN(context.Background(), program, ch, workers))
+		}
+		eq(t,
+			len(results), len(inputs),
+		)
+		for i, result := range results {
+			eq(t,
+				result.Index, i,
+				string(result.Input), string(inputs[i]),
+				result.Match, expected[i],
+			)
+		}
+	}
+}
+
+func TestMatchAllNullable(t *testing.T) {
+	ch := make(chan []byte, 2)
+	ch <- nil
+	ch <- []byte("b")
+	close(ch)
+	results := collectResults(MatchAllN(
+		context.Background(),
+		NewProgram(nil, Optional(Rune('a'))),
+		ch,
+		2,
+	))
+	eq(t,
+		len(results), 2,
+		results[0].Match, true,
+		results[1].Match, false,
+	)
+}
+
+func TestMatchAllInvalidUTF8(t *testing.T) {
+	inputs := []string{
+		"\"\xff\"",
+		`"\ufffd"`,
+		"\"\xef\xbf\xbd\"",
+		"[1, 2]\xff",
+	}
+	ch := make(chan []byte, len(inputs))
+	for _, input := range inputs {
+		ch <- []byte(input)
+	}
+	close(ch)
+	results := collectResults(MatchAllN(
+		context.Background(),
+		NewProgramFromObject(new(JSONParser), Named("Text")),
+		ch,
+		2,
+	))
+	eq(t,
+		len(results), len(inputs),
+		results[0].Match, false,
+		results[1].Match, true,
+		results[2].Match, true,
+		results[3].Match, false,
+	)
+}
+
+func TestMatchAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan []byte)
+	results := MatchAllN(ctx, NewProgram(nil, Rune('a')), ch, 2)
+	ch <- []byte("a")
+	result := <-results
+	eq(t,
+		result.Match, true,
+	)
+	cancel()
+	// closed without closing inputs
+	for range results {
+	}
+}
+
+func collectResults(ch <-chan Result) []Result {
+	var results []Result
+	for result := range ch {
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,100 @@
+package pav
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+type NDJSONRecord struct {
+	// 1-based line number
+	Line int
+	// byte offsets of the record in the stream, excluding the newline, LF or CRLF
+	Start int64
+	End   int64
+	Valid bool
+}
+
+// NDJSONScanner splits newline-delimited JSON into records.
+// A malformed record, including one with invalid UTF-8, does not affect the following lines.
+type NDJSONScanner struct {
+	r      *bufio.Reader
+	vm     *VM
+	offset int64
+	line   int
+	err    error
+}
+
+func NewNDJSONScanner(r io.Reader) *NDJSONScanner {
+	return &NDJSONScanner{
+		r:  bufio.NewReader(r),
+		vm: NewVMFromObject(new(JSONParser), nil),
+	}
+}
+
+// Next returns the next non-blank record, or io.EOF at the end of stream.
+// A read error is returned instead of the record of the partial line.
+func (s *NDJSONScanner) Next() (NDJSONRecord, error) {
+	for {
+		if s.err != nil {
+			return NDJSONRecord{}, s.err
+		}
+
+		s.line++
+		record := NDJSONRecord{
+			Line:  s.line,
+			Start: s.offset,
+		}
+		s.vm.Reset(Named("Text"))
+		blank := true
+		dead := false
+		matched := false
+		cr := false
+
+		for {
+			r, size, err := s.r.ReadRune()
+			if err == io.EOF {
+				s.err = err
+				break
+			} else if err != nil {
+				// no truncated record
+				s.err = err
+				return NDJSONRecord{}, err
+			}
+			if r == '\n' {
+				if cr {
+					// CRLF
+					record.End--
+				}
+				s.offset += int64(size)
+				break
+			}
+			cr = r == '\r'
+			record.End = s.offset + int64(size)
+			s.offset += int64(size)
+			if r != ' ' && r != '\t' && r != '\r' {
+				blank = false
+			}
+			// skip to the next line
+			if dead {
+				continue
+			}
+			if r == utf8.RuneError && size == 1 {
+				// invalid encoding
+				dead = true
+				continue
+			}
+			res := s.vm.Step(r)
+			matched = len(res.Matched) > 0
+			if !matched && len(s.vm.Threads) == 0 {
+				dead = true
+			}
+		}
+
+		if blank {
+			continue
+		}
+		record.Valid = matched && !dead
+		return record, nil
+	}
+}
@@ -0,0 +1,109 @@
+package pav
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONScanner(t *testing.T) {
+	input := `{"a": 1}
+[1, 2
+"foo"
+
+	 
+{"b": [true, null]} ` + "\r\n" + `{"c": }, "d": 1}
+42`
+	s := NewNDJSONScanner(strings.NewReader(input))
+	var records []NDJSONRecord
+	for {
+		record, err := s.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	eq(t,
+		records, []NDJSONRecord{
+			{Line: 1, Start: 0, End: 8, Valid: true},
+			{Line: 2, Start: 9, End: 14, Valid: false},
+			{Line: 3, Start: 15, End: 20, Valid: true},
+			{Line: 6, Start: 25, End: 45, Valid: true},
+			{Line: 7, Start: 47, End: 63, Valid: false},
+			{Line: 8, Start: 64, End: 66, Valid: true},
+		},
+	)
+	for _, record := range records {
+		if strings.Contains(input[record.Start:record.End], "\n") {
+			t.Fatal("bad span")
+		}
+	}
+}
+
+func TestNDJSONScannerCRLF(t *testing.T) {
+	s := NewNDJSONScanner(strings.NewReader("1\r\n[2]\r\n\r\n\"3\r"))
+	var records []NDJSONRecord
+	for {
+		record, err := s.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	eq(t,
+		records, []NDJSONRecord{
+			{Line: 1, Start: 0, End: 1, Valid: true},
+			{Line: 2, Start: 3, End: 6, Valid: true},
+			// not followed by a newline
+			{Line: 4, Start: 10, End: 13, Valid: false},
+		},
+	)
+}
+
+func TestNDJSONScannerReadError(t *testing.T) {
+	s := NewNDJSONScanner(io.MultiReader(
+		strings.NewReader("{\"a\": 1}\n[1, 2"),
+		errReader{},
+	))
+	record, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		record, NDJSONRecord{Line: 1, Start: 0, End: 8, Valid: true},
+	)
+	_, err = s.Next()
+	eq(t,
+		err.Error(), "foo",
+	)
+	_, err = s.Next()
+	eq(t,
+		err.Error(), "foo",
+	)
+}
+
+func TestNDJSONScannerInvalidUTF8(t *testing.T) {
+	s := NewNDJSONScanner(strings.NewReader("\"a\xffb\"\n\"\xef\xbf\xbd\"\n1"))
+	var records []NDJSONRecord
+	for {
+		record, err := s.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	eq(t,
+		records, []NDJSONRecord{
+			{Line: 1, Start: 0, End: 5, Valid: false},
+			// the replacement character itself is valid
+			{Line: 2, Start: 6, End: 11, Valid: true},
+			{Line: 3, Start: 12, End: 13, Valid: true},
+		},
+	)
+}
@@ -0,0 +1,38 @@
+package pav
+
+// Program is a compiled grammar with the start instruction.
+//
+// Instructions are not modified after construction, and clusters are identified by their clone instructions,
+// so a Program and its instructions can be shared by goroutines.
+// A VM is not safe for concurrent use, each goroutine should run its own VM created by NewVM.
+type Program struct {
+	Routines map[string]Routine
+	Start    *Instruction
+	// analysis of Routines and Start shared by VMs, nil if not constructed by NewProgram
+	analysis *Analysis
+}
+
+// NewProgram returns a Program with the routines analyzed once for all VMs of it
+func NewProgram(routines map[string]Routine, start *Instruction) *Program {
+	analysis := Analyze(routines)
+	analysis.node(start)
+	analysis.prepare()
+	return &Program{
+		Routines: routines,
+		Start:    start,
+		analysis: analysis,
+	}
+}
+
+// NewProgramFromObject returns a Program with routines defined by methods of obj, see ObjectRoutines
+func NewProgramFromObject(obj interface{}, start *Instruction) *Program {
+	return NewProgram(ObjectRoutines(obj), start)
+}
+
+// NewVM returns a VM ready to run the program
+func (p *Program) NewVM() *VM {
+	vm := NewVM(p.Routines, p.Start)
+	vm.analysis = p.analysis
+	vm.sharedAnalysis = p.analysis != nil
+	return vm
+}
@@ -0,0 +1,94 @@
+package pav
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestProgramParallel(t *testing.T) {
+	program := NewProgramFromObject(new(JSONParser), Named("Text"))
+	cases := []struct {
+		input string
+		ok    bool
+	}{
+		{`{"foo": [1, 2.5, true, null]}`, true},
+		{`[{}, [], "bar", -1e3]`, true},
+		{`{"foo": }`, false},
+		{`[1, 2,]`, false},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(peek bool) {
+			defer wg.Done()
+			vm := program.NewVM()
+			vm.Capture = true
+			for n := 0; n < 20; n++ {
+				for _, c := range cases {
+					vm.Reset(program.Start)
+					var res StepResult
+					runes := []rune(c.input)
+					for i, r := range runes {
+						if peek {
+							var next rune
+							if i+1 < len(runes) {
+								next = runes[i+1]
+							}
+							res = vm.StepPeek(r, next, i+1 < len(runes))
+						} else {
+							res = vm.Step(r)
+						}
+					}
+					if ok := len(res.Matched) > 0; ok != c.ok {
+						errs <- c.input
+						return
+					}
+				}
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	close(errs)
+	for input := range errs {
+		t.Fatalf("bad result: %s", input)
+	}
+}
+
+func TestProgramClusters(t *testing.T) {
+	// programs constructed concurrently do not share clusters
+	var wg sync.WaitGroup
+	programs := make([]*Program, 8)
+	for i := range programs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			programs[i] = NewProgram(nil, First(Literal("a"), Literal("ab")))
+		}(i)
+	}
+	wg.Wait()
+	for _, program := range programs {
+		vm := program.NewVM()
+		eq(t,
+			match(vm, "a"), true,
+			len(vm.Threads), 0,
+		)
+	}
+}
+
+func TestProgramAnalysis(t *testing.T) {
+	program := NewProgramFromObject(new(JSONParser), Named("Text"))
+	vm := program.NewVM()
+	eq(t,
+		matchInput(vm, program.Start, []byte(`[1, "foo"]`)), true,
+		vm.analysis == program.analysis, true,
+	)
+
+	// instructions not in the program are analyzed by the VM
+	eq(t,
+		matchInput(vm, First(Named("Text"), Literal("foo")), []byte("foo")), true,
+		vm.analysis != program.analysis, true,
+		vm.sharedAnalysis, false,
+	)
+}
@@ -0,0 +1,642 @@
+package pav
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WriteRailroadSVG writes a railroad diagram of the instruction as a standalone SVG document
+func WriteRailroadSVG(w io.Writer, inst *Instruction) error {
+	_, err := io.WriteString(w, railroadSVG(railroadOf(inst), false))
+	return err
+}
+
+// WriteRailroadHTML writes an HTML document containing railroad diagrams of the routines sorted by name.
+// Names in diagrams link to the diagram of the routine.
+func WriteRailroadHTML(w io.Writer, routines map[string]Routine) error {
+	var names []string
+	for name := range routines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b := new(strings.Builder)
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Grammar</title>\n</head>\n<body>\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "<h2 id=\"%s\">%s</h2>\n", html.EscapeString(name), html.EscapeString(name))
+		b.WriteString(railroadSVG(railroadOf(routines[name].Start), true))
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// railroadNode is a combinator recognized from the instruction graph
+type railroadNode struct {
+	kind  string
+	text  string
+	items []*railroadNode
+}
+
+const (
+	railroadTerminal    = "Terminal"
+	railroadNonTerminal = "Named"
+	railroadSkip        = "Skip"
+	railroadSeq         = "Seq"
+	railroadFirst       = "First"
+	railroadLongest     = "Longest"
+	railroadOptional    = "Optional"
+	railroadZeroOrMore  = "ZeroOrMore"
+	railroadOneOrMore   = "OneOrMore"
+	railroadPredict     = "Predict"
+)
+
+func (n *railroadNode) String() string {
+	switch n.kind {
+	case railroadTerminal, railroadNonTerminal, railroadPredict:
+		return n.kind + "(" + n.text + ")"
+	case railroadSkip:
+		return n.kind
+	}
+	var items []string
+	for _, item := range n.items {
+		items = append(items, item.String())
+	}
+	return n.kind + "(" + strings.Join(items, ", ") + ")"
+}
+
+func railroadOf(inst *Instruction) *railroadNode {
+	c := &railroadConverter{
+		visiting: make(map[*Instruction]bool),
+	}
+	return c.seq(inst)
+}
+
+type railroadConverter struct {
+	visiting map[*Instruction]bool
+}
+
+// seq converts the instruction and its Next chain
+func (c *railroadConverter) seq(inst *Instruction) *railroadNode {
+	var items []*railroadNode
+	for inst != nil {
+		if c.visiting[inst] {
+			items = append(items, &railroadNode{
+				kind: railroadNonTerminal,
+				text: "...",
+			})
+			break
+		}
+		c.visiting[inst] = true
+		defer delete(c.visiting, inst)
+		var item *railroadNode
+		item, inst = c.item(inst)
+		if item.kind == railroadSeq {
+			items = append(items, item.items...)
+		} else if item.kind != railroadSkip {
+			items = append(items, item)
+		}
+	}
+	switch len(items) {
+	case 0:
+		return &railroadNode{
+			kind: railroadSkip,
+		}
+	case 1:
+		return items[0]
+	}
+	return &railroadNode{
+		kind:  railroadSeq,
+		items: items,
+	}
+}
+
+// item converts the instruction and returns the next instruction of the sequence
+func (c *railroadConverter) item(inst *Instruction) (*railroadNode, *Instruction) {
+	if node := c.combinator(inst); node != nil {
+		return node, nil
+	}
+
+	switch inst.Op {
+
+	case OpRune:
+		if inst.Predict {
+			node := &railroadNode{
+				kind: railroadSeq,
+				items: []*railroadNode{
+					{
+						kind: railroadPredict,
+						text: runeClass(inst),
+					},
+				},
+			}
+			if cont := c.seq(inst.Inst); cont.kind == railroadSeq {
+				node.items = append(node.items, cont.items...)
+			} else if cont.kind != railroadSkip {
+				node.items = append(node.items, cont)
+			}
+			return node, nil
+		}
+		if isPlainRune(inst) {
+			// literal
+			var runes []rune
+			for ; inst != nil && isPlainRune(inst); inst = inst.Next {
+				runes = append(runes, inst.Rune)
+			}
+			return &railroadNode{
+				kind: railroadTerminal,
+				text: strconv.Quote(string(runes)),
+			}, inst
+		}
+		return &railroadNode{
+			kind: railroadTerminal,
+			text: runeClass(inst),
+		}, inst.Next
+
+	case OpCall:
+		if inst.Inst == nil {
+			return &railroadNode{
+				kind: railroadNonTerminal,
+				text: inst.Name,
+			}, inst.Next
+		}
+		// OneOrMore(x) is Seq(x, ZeroOrMore(x))
+		if next := inst.Next; next != nil && next.Op == OpCall && next.Inst != nil {
+			if body := zeroOrMoreBody(next.Inst); body != nil && body == inst.Inst {
+				return &railroadNode{
+					kind:  railroadOneOrMore,
+					items: []*railroadNode{c.seq(body)},
+				}, next.Next
+			}
+		}
+		return c.seq(inst.Inst), inst.Next
+
+	case OpClone:
+		if body := zeroOrMoreBody(inst); body != nil {
+			return &railroadNode{
+				kind:  railroadZeroOrMore,
+				items: []*railroadNode{c.seq(body)},
+			}, inst.Next
+		}
+		if len(inst.Insts) == 2 && inst.Insts[0] == nil && inst.ClusterType == 0 &&
+			inst.Insts[1].Op == OpCall && inst.Insts[1].Inst != nil && inst.Insts[1].Next == nil {
+			return &railroadNode{
+				kind:  railroadOptional,
+				items: []*railroadNode{c.seq(inst.Insts[1].Inst)},
+			}, inst.Next
+		}
+		node := &railroadNode{
+			kind: railroadLongest,
+		}
+		if inst.ClusterType == ClusterShortest {
+			node.kind = railroadFirst
+		}
+		for _, branch := range inst.Insts {
+			node.items = append(node.items, c.seq(branch))
+		}
+		return node, inst.Next
+
+	case OpJump:
+		return c.seq(inst.Inst), nil
+
+	case OpIndirect:
+		return c.seq(*inst.InstP), nil
+
+	}
+
+	return &railroadNode{
+		kind: railroadSkip,
+	}, nil
+}
+
+// combinator converts the instruction by its recorded combinator, returns nil if not recorded
+func (c *railroadConverter) combinator(inst *Instruction) *railroadNode {
+	operands := func(kind string) *railroadNode {
+		node := &railroadNode{
+			kind: kind,
+		}
+		for _, operand := range inst.Operands {
+			node.items = append(node.items, c.seq(operand))
+		}
+		return node
+	}
+
+	switch inst.Combinator {
+
+	case CombLiteral, CombRuneSeq:
+		var runes []rune
+		for i := inst; i != nil; i = i.Next {
+			runes = append(runes, i.Rune)
+		}
+		return &railroadNode{
+			kind: railroadTerminal,
+			text: strconv.Quote(string(runes)),
+		}
+
+	case CombRune:
+		if isPlainRune(inst) {
+			return &railroadNode{
+				kind: railroadTerminal,
+				text: strconv.Quote(string(inst.Rune)),
+			}
+		}
+		fallthrough
+	case CombRuneSet, CombRuneRange, CombAnyRune, CombRuneCategory, CombRuneInverse, CombRuneClass:
+		return &railroadNode{
+			kind: railroadTerminal,
+			text: runeClass(inst),
+		}
+
+	case CombKeywords, CombLongestLiteral:
+		node := &railroadNode{
+			kind: railroadFirst,
+		}
+		if inst.Combinator == CombLongestLiteral {
+			node.kind = railroadLongest
+		}
+		for _, word := range trieWords(inst, nil, nil) {
+			node.items = append(node.items, &railroadNode{
+				kind: railroadTerminal,
+				text: strconv.Quote(word),
+			})
+		}
+		if len(node.items) == 1 {
+			return node.items[0]
+		}
+		return node
+
+	case CombNamed:
+		return &railroadNode{
+			kind: railroadNonTerminal,
+			text: inst.Name,
+		}
+
+	case CombSeq:
+		node := c.seq(nil)
+		for _, operand := range inst.Operands {
+			item := c.seq(operand)
+			if item.kind == railroadSkip {
+				continue
+			}
+			if node.kind == railroadSkip {
+				node = item
+				continue
+			}
+			if node.kind != railroadSeq {
+				node = &railroadNode{
+					kind:  railroadSeq,
+					items: []*railroadNode{node},
+				}
+			}
+			if item.kind == railroadSeq {
+				node.items = append(node.items, item.items...)
+			} else {
+				node.items = append(node.items, item)
+			}
+		}
+		return node
+
+	case CombFirst, CombShortest:
+		return operands(railroadFirst)
+
+	case CombLongest:
+		return operands(railroadLongest)
+
+	case CombOptional:
+		return operands(railroadOptional)
+
+	case CombZeroOrMore:
+		return operands(railroadZeroOrMore)
+
+	case CombOneOrMore:
+		return operands(railroadOneOrMore)
+
+	}
+
+	// indirect and predict are converted from the graph
+	return nil
+}
+
+// zeroOrMoreBody returns x if the instruction is ZeroOrMore(x)
+func zeroOrMoreBody(inst *Instruction) *Instruction {
+	if inst.Op != OpClone || inst.ClusterType != 0 || len(inst.Insts) != 2 || inst.Insts[0] != nil {
+		return nil
+	}
+	more := inst.Insts[1]
+	if more.Op != OpCall || more.Inst == nil || more.Next == nil ||
+		more.Next.Op != OpIndirect || *more.Next.InstP != inst {
+		return nil
+	}
+	return more.Inst
+}
+
+func isPlainRune(inst *Instruction) bool {
+	return inst.Op == OpRune && len(inst.Runes) == 0 && inst.RuneRange[0] == inst.RuneRange[1] &&
+		inst.Category == "" && inst.Table == nil && !inst.Inverse && !inst.Predict
+}
+
+// runeClass describes the runes matched by the instruction
+func runeClass(inst *Instruction) string {
+	var s string
+	if tableOnly(inst) {
+		for _, r := range inst.Table.Ranges {
+			if r[1]-r[0] >= 2 {
+				s += classRune(r[0]) + "-" + classRune(r[1])
+			} else {
+				for c := r[0]; c <= r[1]; c++ {
+					s += classRune(c)
+				}
+			}
+		}
+		s = "[" + s + "]"
+	} else if len(inst.Runes) > 0 {
+		runes := make([]rune, len(inst.Runes))
+		copy(runes, inst.Runes)
+		sort.Slice(runes, func(i, j int) bool {
+			return runes[i] < runes[j]
+		})
+		for i := 0; i < len(runes); {
+			j := i
+			for j+1 < len(runes) && runes[j+1] <= runes[j]+1 {
+				j++
+			}
+			if runes[j]-runes[i] >= 2 {
+				// abbreviate runs
+				s += classRune(runes[i]) + "-" + classRune(runes[j])
+			} else {
+				for k := i; k <= j; k++ {
+					if k == i || runes[k] != runes[k-1] {
+						s += classRune(runes[k])
+					}
+				}
+			}
+			i = j + 1
+		}
+		s = "[" + s + "]"
+	} else if inst.RuneRange[0] != inst.RuneRange[1] {
+		if inst.RuneRange[0] == 0 && inst.RuneRange[1] == unicode.MaxRune {
+			if inst.Inverse {
+				return "none"
+			}
+			return "any"
+		}
+		s = "[" + classRune(inst.RuneRange[0]) + "-" + classRune(inst.RuneRange[1]) + "]"
+	} else if inst.Category != "" {
+		s = `\p{` + inst.Category + `}`
+		if inst.Inverse {
+			return `\P{` + inst.Category + `}`
+		}
+	} else {
+		s = "[" + classRune(inst.Rune) + "]"
+	}
+	if inst.Inverse {
+		s = "[^" + s[1:]
+	}
+	return s
+}
+
+func classRune(r rune) string {
+	switch r {
+	case '\\', ']', '-', '^':
+		return `\` + string(r)
+	}
+	q := strconv.QuoteRune(r)
+	if !utf8.ValidRune(r) {
+		q = fmt.Sprintf(`'\x{%x}'`, r)
+	}
+	q = q[1 : len(q)-1]
+	if q == `\'` {
+		q = "'"
+	}
+	return q
+}
+
+const (
+	railroadArc       = 10
+	railroadGap       = 10
+	railroadBoxHeight = 22
+	railroadRuneWidth = 9
+	railroadMargin    = 20
+)
+
+// railroadLayout is the size of a diagram element, entering and exiting at the baseline
+type railroadLayout struct {
+	width, up, down int
+}
+
+func (n *railroadNode) layout() railroadLayout {
+	switch n.kind {
+
+	case railroadTerminal, railroadNonTerminal, railroadPredict:
+		return railroadLayout{
+			width: utf8.RuneCountInString(n.label())*railroadRuneWidth + 20,
+			up:    railroadBoxHeight / 2,
+			down:  railroadBoxHeight / 2,
+		}
+
+	case railroadSeq:
+		var l railroadLayout
+		for i, item := range n.items {
+			il := item.layout()
+			if i > 0 {
+				l.width += railroadGap
+			}
+			l.width += il.width
+			if il.up > l.up {
+				l.up = il.up
+			}
+			if il.down > l.down {
+				l.down = il.down
+			}
+		}
+		return l
+
+	case railroadFirst, railroadLongest, railroadOptional, railroadZeroOrMore:
+		items := n.choices()
+		var l railroadLayout
+		ys := choiceOffsets(items)
+		for i, item := range items {
+			il := item.layout()
+			if il.width > l.width {
+				l.width = il.width
+			}
+			if i == 0 {
+				l.up = il.up
+			}
+			l.down = ys[i] + il.down
+		}
+		l.width += railroadArc * 4
+		return l
+
+	case railroadOneOrMore:
+		il := n.items[0].layout()
+		return railroadLayout{
+			width: il.width + railroadArc*4,
+			up:    il.up,
+			down:  loopOffset(il),
+		}
+
+	}
+	return railroadLayout{}
+}
+
+func (n *railroadNode) label() string {
+	if n.kind == railroadPredict {
+		return "&" + n.text
+	}
+	return n.text
+}
+
+// choices returns alternatives of choice-like nodes, the first one is on the baseline
+func (n *railroadNode) choices() []*railroadNode {
+	switch n.kind {
+	case railroadOptional:
+		return []*railroadNode{{kind: railroadSkip}, n.items[0]}
+	case railroadZeroOrMore:
+		return []*railroadNode{{kind: railroadSkip}, {kind: railroadOneOrMore, items: n.items}}
+	}
+	return n.items
+}
+
+// choiceOffsets returns baseline offsets of alternatives
+func choiceOffsets(items []*railroadNode) []int {
+	ys := make([]int, len(items))
+	for i := 1; i < len(items); i++ {
+		y := ys[i-1] + items[i-1].layout().down + railroadGap + items[i].layout().up
+		if min := ys[i-1] + railroadArc*2; y < min {
+			y = min
+		}
+		ys[i] = y
+	}
+	return ys
+}
+
+// loopOffset returns the offset of the loop back line of OneOrMore
+func loopOffset(l railroadLayout) int {
+	y := l.down + railroadGap
+	if y < railroadArc*2 {
+		y = railroadArc * 2
+	}
+	return y
+}
+
+func (n *railroadNode) draw(b *strings.Builder, x, y int, links bool) {
+	l := n.layout()
+	switch n.kind {
+
+	case railroadTerminal, railroadNonTerminal, railroadPredict:
+		rx := 0
+		if n.kind != railroadNonTerminal {
+			rx = railroadBoxHeight / 2
+		}
+		class := strings.ToLower(n.kind)
+		if links && n.kind == railroadNonTerminal && n.text != "..." {
+			fmt.Fprintf(b, "<a href=\"#%s\">", html.EscapeString(n.text))
+		}
+		fmt.Fprintf(b, "<rect class=\"%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"%d\"/>",
+			class, x, y-railroadBoxHeight/2, l.width, railroadBoxHeight, rx)
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s</text>", x+l.width/2, y+5, html.EscapeString(n.label()))
+		if links && n.kind == railroadNonTerminal && n.text != "..." {
+			b.WriteString("</a>")
+		}
+		b.WriteString("\n")
+
+	case railroadSeq:
+		for i, item := range n.items {
+			if i > 0 {
+				railroadLine(b, x, y, x+railroadGap, y)
+				x += railroadGap
+			}
+			item.draw(b, x, y, links)
+			x += item.layout().width
+		}
+
+	case railroadFirst, railroadLongest, railroadOptional, railroadZeroOrMore:
+		items := n.choices()
+		ys := choiceOffsets(items)
+		inner := l.width - railroadArc*4
+		a := railroadArc
+		for i, item := range items {
+			iy := y + ys[i]
+			if i == 0 {
+				railroadLine(b, x, y, x+2*a, y)
+			} else {
+				fmt.Fprintf(b, "<path d=\"M%d %dQ%d %d %d %dL%d %dQ%d %d %d %d\"/>\n",
+					x, y, x+a, y, x+a, y+a, x+a, iy-a, x+a, iy, x+2*a, iy)
+			}
+			item.draw(b, x+2*a, iy, links)
+			end := x + 2*a + item.layout().width
+			railroadLine(b, end, iy, x+2*a+inner, iy)
+			if i == 0 {
+				railroadLine(b, x+2*a+inner, y, x+l.width, y)
+			} else {
+				right := x + 2*a + inner
+				fmt.Fprintf(b, "<path d=\"M%d %dQ%d %d %d %dL%d %dQ%d %d %d %d\"/>\n",
+					right, iy, right+a, iy, right+a, iy-a, right+a, y+a, right+a, y, right+2*a, y)
+			}
+		}
+
+	case railroadOneOrMore:
+		item := n.items[0]
+		il := item.layout()
+		a := railroadArc
+		railroadLine(b, x, y, x+2*a, y)
+		item.draw(b, x+2*a, y, links)
+		right := x + 2*a + il.width
+		railroadLine(b, right, y, right+2*a, y)
+		ly := y + loopOffset(il)
+		fmt.Fprintf(b, "<path d=\"M%d %dQ%d %d %d %dL%d %dQ%d %d %d %dL%d %dQ%d %d %d %dL%d %dQ%d %d %d %d\"/>\n",
+			right, y, right+a, y, right+a, y+a,
+			right+a, ly-a, right+a, ly, right, ly,
+			x+2*a, ly, x+a, ly, x+a, ly-a,
+			x+a, y+a, x+a, y, x+2*a, y,
+		)
+
+	case railroadSkip:
+		railroadLine(b, x, y, x+l.width, y)
+
+	}
+}
+
+func railroadLine(b *strings.Builder, x1, y1, x2, y2 int) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	fmt.Fprintf(b, "<path d=\"M%d %dL%d %d\"/>\n", x1, y1, x2, y2)
+}
+
+const railroadStyle = `<style>
+path { stroke: black; stroke-width: 2; fill: none; }
+rect { stroke: black; stroke-width: 2; fill: #ffc; }
+rect.named { fill: #cdf; }
+rect.predict { fill: #eee; stroke-dasharray: 4 2; }
+text { font: 14px monospace; text-anchor: middle; }
+</style>
+`
+
+func railroadSVG(node *railroadNode, inline bool) string {
+	l := node.layout()
+	m := railroadMargin
+	width := l.width + m*2 + railroadGap*2
+	height := l.up + l.down + m*2
+	b := new(strings.Builder)
+	if !inline {
+		b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	}
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	b.WriteString(railroadStyle)
+	y := m + l.up
+	// start and end markers
+	fmt.Fprintf(b, "<path d=\"M%d %dL%d %dM%d %dL%d %d\"/>\n", m, y-8, m, y+8, m, y, m+railroadGap, y)
+	node.draw(b, m+railroadGap, y, inline)
+	end := m + railroadGap + l.width
+	fmt.Fprintf(b, "<path d=\"M%d %dL%d %dM%d %dL%d %d\"/>\n", end, y, end+railroadGap, y, end+railroadGap, y-8, end+railroadGap, y+8)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
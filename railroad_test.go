@@ -0,0 +1,113 @@
+package pav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRailroadOf(t *testing.T) {
+	json := ObjectRoutines(new(JSONParser))
+	grammar := ObjectRoutines(new(TextGrammarParser))
+	eq(t,
+		railroadOf(json["Blank"].Start).String(),
+		`ZeroOrMore(Terminal([\t\n\r ]))`,
+		railroadOf(json["Number"].Start).String(),
+		`Seq(Optional(Terminal("-")), Longest(Terminal("0"), Seq(Terminal([1-9]), ZeroOrMore(Terminal([0-9])))), `+
+			`Optional(Seq(Terminal("."), OneOrMore(Terminal([0-9])))), `+
+			`Optional(Seq(Longest(Terminal("e"), Terminal("E")), Optional(Longest(Terminal("+"), Terminal("-"))), OneOrMore(Terminal([0-9])))))`,
+		railroadOf(json["HexDigit"].Start).String(),
+		`First(Terminal([0-9]), Terminal([a-f]), Terminal([A-F]))`,
+		railroadOf(grammar["Name"].Start).String(),
+		`Seq(Terminal([A-Z_a-z]), ZeroOrMore(Terminal([0-9A-Z_a-z])), Predict([^0-9A-Z_a-z]))`,
+		railroadOf(grammar["Char"].Start).String(),
+		`Longest(Seq(Terminal("\\"), Longest(Terminal(["'\-[-\]nrt]), Seq(Terminal("u"), Named(HexDigit), Named(HexDigit), Named(HexDigit), Named(HexDigit)))), Terminal([^\n\\]))`,
+		railroadOf(Seq(AnyRune(), RuneCategory("L"), RuneInverse(RuneCategory("L")), emptyInstruction())).String(),
+		`Seq(Terminal(any), Terminal(\p{L}), Terminal(\P{L}))`,
+		railroadOf(Seq(Keywords("go", "if"), LongestLiteral("x"))).String(),
+		`Seq(First(Terminal("go"), Terminal("if")), Terminal("x"))`,
+	)
+
+	var inst *Instruction
+	inst = Seq(Rune('a'), Indirect(&inst))
+	eq(t,
+		railroadOf(inst).String(), `Seq(Terminal("a"), Named(...))`,
+	)
+
+	// without recorded combinators
+	eq(t,
+		railroadOf(&Instruction{
+			Op:   OpRune,
+			Rune: 'a',
+			Next: &Instruction{
+				Op:   OpRune,
+				Rune: 'b',
+				Next: &Instruction{
+					Op:   OpCall,
+					Name: "C",
+				},
+			},
+		}).String(),
+		`Seq(Terminal("ab"), Named(C))`,
+	)
+}
+
+func wellFormed(t *testing.T, doc string) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.AutoClose = []string{"meta"}
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWriteRailroadSVG(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteRailroadSVG(buf, ObjectRoutines(new(JSONParser))["Object"].Start); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	wellFormed(t, svg)
+	for _, s := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<text x="`,
+		`>&#34;{&#34;</text>`,
+		`<rect class="named"`,
+	} {
+		if !strings.Contains(svg, s) {
+			t.Fatalf("expecting %s", s)
+		}
+	}
+	if strings.Contains(svg, "<a ") {
+		t.Fatal("should not contain links")
+	}
+}
+
+func TestWriteRailroadHTML(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteRailroadHTML(buf, ObjectRoutines(new(JSONParser))); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+	wellFormed(t, doc)
+	for _, s := range []string{
+		`<h2 id="Array">Array</h2>`,
+		`<h2 id="Value">Value</h2>`,
+		`<a href="#Value"><rect class="named"`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Fatalf("expecting %s", s)
+		}
+	}
+	if strings.Index(doc, `id="Array"`) > strings.Index(doc, `id="Value"`) {
+		t.Fatal("should be sorted")
+	}
+}
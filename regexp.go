@@ -0,0 +1,175 @@
+package pav
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+)
+
+// CompileRegexp compiles a RE2 pattern to an instruction matching the whole pattern.
+// Alternations compile to Longest, empty-width assertions are not supported.
+func CompileRegexp(pattern string) (*Instruction, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return compileRegexp(re.Simplify())
+}
+
+func compileRegexp(re *syntax.Regexp) (*Instruction, error) {
+	switch re.Op {
+
+	case syntax.OpNoMatch:
+		return RuneInverse(AnyRune()), nil
+
+	case syntax.OpEmptyMatch:
+		return emptyInstruction(), nil
+
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 {
+			return RuneSeq(re.Rune), nil
+		}
+		insts := make([]*Instruction, 0, len(re.Rune))
+		for _, r := range re.Rune {
+			folds := []rune{r}
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				folds = append(folds, f)
+			}
+			if len(folds) == 1 {
+				insts = append(insts, Rune(r))
+			} else {
+				insts = append(insts, RuneSet(folds...))
+			}
+		}
+		if len(insts) == 1 {
+			return insts[0], nil
+		}
+		return Seq(insts...), nil
+
+	case syntax.OpCharClass:
+		var ranges [][2]rune
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			ranges = append(ranges, [2]rune{re.Rune[i], re.Rune[i+1]})
+		}
+		// prefer inverse instruction for negated classes
+		complement := complementRanges(normalizeRanges(ranges))
+		if len(complement) < len(ranges) {
+			return runeRanges(complement, true), nil
+		}
+		return runeRanges(ranges, false), nil
+
+	case syntax.OpAnyCharNotNL:
+		return RuneInverse(Rune('\n')), nil
+
+	case syntax.OpAnyChar:
+		return AnyRune(), nil
+
+	case syntax.OpCapture:
+		return compileRegexp(re.Sub[0])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		body := re.Sub[0]
+		// x+ is x* if x matches empty
+		star := re.Op == syntax.OpStar || re.Op == syntax.OpPlus && regexpNullable(body)
+		if re.Op != syntax.OpQuest {
+			// x* is y*, and x+ is y+ if x does not match empty
+			body = regexpStarBody(body)
+		}
+		sub, err := compileRegexp(body)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case star:
+			return ZeroOrMore(sub), nil
+		case re.Op == syntax.OpPlus:
+			return OneOrMore(sub), nil
+		}
+		return Optional(sub), nil
+
+	case syntax.OpConcat, syntax.OpAlternate:
+		insts := make([]*Instruction, 0, len(re.Sub))
+		for _, sub := range re.Sub {
+			inst, err := compileRegexp(sub)
+			if err != nil {
+				return nil, err
+			}
+			insts = append(insts, inst)
+		}
+		if len(insts) == 1 {
+			return insts[0], nil
+		}
+		if re.Op == syntax.OpConcat {
+			return Seq(insts...), nil
+		}
+		return Longest(insts...), nil
+
+	}
+
+	return nil, fmt.Errorf("not supported: %s", re)
+}
+
+// regexpNullable reports whether the regexp matches empty string
+func regexpNullable(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpCapture, syntax.OpPlus:
+		return regexpNullable(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || regexpNullable(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !regexpNullable(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if regexpNullable(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// regexpStarBody returns a non-empty regexp y that y* is x*.
+// A repeated body matching empty loops without consuming runes, and nested repetitions match a run in many ways,
+// both multiply threads.
+func regexpStarBody(x *syntax.Regexp) *syntax.Regexp {
+	switch x.Op {
+
+	case syntax.OpEmptyMatch:
+		return &syntax.Regexp{
+			Op: syntax.OpNoMatch,
+		}
+
+	case syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		// (x*)* (x+)* (x?)* are x*
+		return regexpStarBody(x.Sub[0])
+
+	case syntax.OpConcat:
+		if regexpNullable(x) {
+			// (xy)* is (x|y)* if x and y match empty
+			return regexpStarBody(&syntax.Regexp{
+				Op:  syntax.OpAlternate,
+				Sub: x.Sub,
+			})
+		}
+
+	case syntax.OpAlternate:
+		// (x*|y)* is (x|y)*
+		subs := make([]*syntax.Regexp, 0, len(x.Sub))
+		for _, sub := range x.Sub {
+			subs = append(subs, regexpStarBody(sub))
+		}
+		return &syntax.Regexp{
+			Op:  syntax.OpAlternate,
+			Sub: subs,
+		}
+
+	}
+	return x
+}
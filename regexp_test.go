@@ -0,0 +1,144 @@
+package pav
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCompileRegexp(t *testing.T) {
+	inputs := []string{
+		"", "a", "b", "ab", "abc", "aab", "abab", "A", "Ab", "aB",
+		"0", "42", "-42", "3.14", "1e10", "x", "\n", "a\nb",
+		"foo", "foobar", "FOO", "bar", "baz", "é", "中文",
+		"[]", "a-z", "hello world", "  ", "\t",
+	}
+	for _, pattern := range []string{
+		`a`,
+		`ab`,
+		`a|b`,
+		`a*`,
+		`a+b`,
+		`(ab)+`,
+		`a?b?c?`,
+		`[a-c]+`,
+		`[^a]`,
+		`[^a-c\n]+`,
+		`\d+`,
+		`-?\d+(\.\d+)?([eE][+-]?\d+)?`,
+		`(?i)foo`,
+		`(?i)ab`,
+		`.`,
+		`(?s).+`,
+		`a.b`,
+		`foo|foobar|bar`,
+		`ba[rz]`,
+		`\w+ \w+`,
+		`\s*`,
+		`[[:alpha:]]+`,
+		`\pL+`,
+		`a{2}b`,
+		`(a|ab)(c|bcd)?`,
+		`x*|y`,
+		`[^\x00-\x{10FFFF}]`,
+		`(a*)*b`,
+		`((a*)*)*`,
+		`(a*|b)*`,
+		`(a*b*)*c`,
+		`(a?b?)+`,
+		`(a+)+b`,
+		`(a|b|)+`,
+		`(|a)*`,
+		`x(?:y|)`,
+		`x(?:y|z*)`,
+		`a(?:b|c?)(?:d|)`,
+	} {
+		inst, err := CompileRegexp(pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for _, input := range inputs {
+			vm := &VM{
+				Threads: []*Thread{
+					{
+						PC: inst,
+					},
+				},
+			}
+			if input == "" {
+				// no rune to step
+				continue
+			}
+			if ok := match(vm, input); ok != re.MatchString(input) {
+				t.Errorf("%s %q: got %v", pattern, input, ok)
+			}
+		}
+	}
+}
+
+func TestCompileRegexpInGrammar(t *testing.T) {
+	number, err := CompileRegexp(`-?\d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := &VM{
+		Threads: []*Thread{
+			{
+				PC: Seq(
+					Rune('['),
+					number,
+					ZeroOrMore(
+						Seq(
+							Rune(','),
+							number,
+						),
+					),
+					Rune(']'),
+				),
+			},
+		},
+	}
+	eq(t,
+		match(vm, "[1,-2,345]"), true,
+	)
+}
+
+func TestCompileRegexpError(t *testing.T) {
+	for _, pattern := range []string{
+		`^a`,
+		`a$`,
+		`\bfoo`,
+		`(`,
+	} {
+		if _, err := CompileRegexp(pattern); err == nil {
+			t.Errorf("%s: should fail", pattern)
+		}
+	}
+}
+
+func TestCompileRegexpNestedRepetition(t *testing.T) {
+	// nested repetitions of the same runs do not multiply threads
+	for _, pattern := range []string{
+		`(a*)*b`,
+		`(a*|b)*b`,
+		`(a*b*)*b`,
+		`(a+)+b`,
+	} {
+		inst, err := CompileRegexp(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vm := NewVM(nil, inst)
+		var res StepResult
+		for _, r := range strings.Repeat("a", 10000) + "b" {
+			res = vm.Step(r)
+			if len(vm.Threads) > 8 {
+				t.Fatalf("%s: %d threads", pattern, len(vm.Threads))
+			}
+		}
+		eq(t,
+			len(res.Matched) > 0, true,
+		)
+	}
+}
@@ -0,0 +1,91 @@
+package pav
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// steps between checks of the context in RunContext
+const contextCheckSteps = 1024
+
+// ErrInvalidUTF8 is returned by RunContext and JSONDecoder if the input is not valid UTF-8
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// OffsetError is an error occurred at the byte offset of the input
+type OffsetError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
+
+// Run is RunContext with the background context
+func (v *VM) Run(r io.Reader) (bool, error) {
+	return v.RunContext(context.Background(), r)
+}
+
+// RunContext steps runes read from r until EOF or no thread left, and reports whether the whole input is matched.
+// The VM continues from its current threads, so it must be newly created or Reset to the start instruction.
+// The context is checked every contextCheckSteps steps.
+// Errors of ctx and r, and ErrInvalidUTF8 for invalid encodings, are returned as *OffsetError with the offset of the first rune not stepped.
+func (v *VM) RunContext(ctx context.Context, r io.Reader) (bool, error) {
+	runes, ok := r.(io.RuneReader)
+	if !ok {
+		runes = bufio.NewReader(r)
+	}
+	var offset int64
+	matched := false
+	for steps := 0; ; steps++ {
+		if steps%contextCheckSteps == 0 {
+			select {
+			case <-ctx.Done():
+				return false, &OffsetError{
+					Offset: offset,
+					Err:    ctx.Err(),
+				}
+			default:
+			}
+		}
+		c, size, err := runes.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return false, &OffsetError{
+				Offset: offset,
+				Err:    err,
+			}
+		}
+		if c == utf8.RuneError && size == 1 {
+			return false, &OffsetError{
+				Offset: offset,
+				Err:    ErrInvalidUTF8,
+			}
+		}
+		if len(v.Threads) == 0 {
+			// remaining input not matched
+			return false, nil
+		}
+		res := v.Step(c)
+		matched = len(res.Matched) > 0
+		offset += int64(size)
+	}
+	if offset == 0 {
+		// threads match only after stepping, empty input is matched if the start is nullable
+		for _, thread := range v.Threads {
+			if thread.Stack == nil && v.analysisOf(thread.PC).Nullable(thread.PC) {
+				return true, nil
+			}
+		}
+	}
+	return matched, nil
+}
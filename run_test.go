@@ -0,0 +1,134 @@
+package pav
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		ok    bool
+	}{
+		{`{"foo": [1, 2, 3]}`, true},
+		{`{"foo": [1, 2, 3]} x`, false},
+		{`{"foo": [1, 2, 3]`, false},
+		{`{"foo": ]`, false},
+		{``, false},
+	} {
+		vm := NewVMFromObject(new(JSONParser), Named("Text"))
+		ok, err := vm.Run(strings.NewReader(c.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t,
+			ok, c.ok,
+		)
+	}
+
+	// empty input
+	for _, c := range []struct {
+		inst *Instruction
+		ok   bool
+	}{
+		{Optional(Rune('a')), true},
+		{ZeroOrMore(Named("A")), true},
+		{Named("A"), false},
+	} {
+		vm := NewVM(map[string]Routine{
+			"A": {Start: Rune('a')},
+		}, c.inst)
+		ok, err := vm.Run(strings.NewReader(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eq(t,
+			ok, c.ok,
+		)
+	}
+}
+
+// cancelReader cancels the context after n bytes read
+type cancelReader struct {
+	r      io.Reader
+	n      int
+	cancel func()
+}
+
+func (c *cancelReader) Read(buf []byte) (int, error) {
+	if c.n <= 0 {
+		c.cancel()
+	}
+	if len(buf) > c.n && c.n > 0 {
+		buf = buf[:c.n]
+	}
+	n, err := c.r.Read(buf)
+	c.n -= n
+	return n, err
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	input := "[" + strings.Repeat("1, ", 10000) + "1]"
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	_, err := vm.RunContext(ctx, &cancelReader{
+		r:      strings.NewReader(input),
+		n:      3000,
+		cancel: cancel,
+	})
+	var offsetErr *OffsetError
+	if !errors.As(err, &offsetErr) {
+		t.Fatalf("got %v", err)
+	}
+	eq(t,
+		errors.Is(err, context.Canceled), true,
+		offsetErr.Offset%contextCheckSteps, int64(0),
+		offsetErr.Offset > 3000, true,
+		err.Error(), "at offset 3072: context canceled",
+	)
+
+	// canceled before running
+	vm = NewVMFromObject(new(JSONParser), Named("Text"))
+	_, err = vm.RunContext(ctx, strings.NewReader(input))
+	eq(t,
+		err.Error(), "at offset 0: context canceled",
+	)
+
+	// read error
+	vm = NewVMFromObject(new(JSONParser), Named("Text"))
+	_, err = vm.RunContext(context.Background(), io.MultiReader(
+		strings.NewReader("[1, "),
+		errReader{},
+	))
+	eq(t,
+		err.Error(), "at offset 4: foo",
+	)
+}
+
+func TestRunInvalidUTF8(t *testing.T) {
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	_, err := vm.Run(strings.NewReader("[\"a\xffb\"]"))
+	eq(t,
+		errors.Is(err, ErrInvalidUTF8), true,
+		err.Error(), "at offset 3: invalid UTF-8",
+	)
+
+	// the replacement character itself is valid
+	vm = NewVMFromObject(new(JSONParser), Named("Text"))
+	ok, err := vm.Run(strings.NewReader("[\"a\ufffdb\"]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		ok, true,
+	)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("foo")
+}
@@ -0,0 +1,131 @@
+package pav
+
+import (
+	"sync"
+	"unicode"
+)
+
+// RuneTable is a set of runes in sorted ranges, with a bitmap for ASCII runes
+type RuneTable struct {
+	ascii [2]uint64
+	// sorted, non-overlapping and non-adjacent inclusive ranges
+	Ranges [][2]rune
+}
+
+// NewRuneTable returns a table of runes in the inclusive ranges
+func NewRuneTable(ranges [][2]rune) *RuneTable {
+	t := &RuneTable{
+		Ranges: normalizeRanges(ranges),
+	}
+	for _, r := range t.Ranges {
+		for c := r[0]; c <= r[1] && c < 128; c++ {
+			t.ascii[c>>6] |= uint64(1) << (uint(c) & 63)
+		}
+	}
+	return t
+}
+
+func (t *RuneTable) Contains(r rune) bool {
+	if r >= 0 && r < 128 {
+		return t.ascii[r>>6]&(uint64(1)<<(uint(r)&63)) != 0
+	}
+	return t.index(r) >= 0
+}
+
+// index returns the index of the range containing r, or -1
+func (t *RuneTable) index(r rune) int {
+	lo, hi := 0, len(t.Ranges)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if r < t.Ranges[m][0] {
+			hi = m
+		} else if r > t.Ranges[m][1] {
+			lo = m + 1
+		} else {
+			return m
+		}
+	}
+	return -1
+}
+
+// containsTable reports whether all runes of u are in t
+func (t *RuneTable) containsTable(u *RuneTable) bool {
+	for _, r := range u.Ranges {
+		i := t.index(r[0])
+		if i < 0 || r[1] > t.Ranges[i][1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *RuneTable) complement() *RuneTable {
+	return NewRuneTable(complementRanges(t.Ranges))
+}
+
+var categoryTables sync.Map
+
+// categoryTable returns the table of the unicode category, or nil if not exists
+func categoryTable(category string) *RuneTable {
+	if v, ok := categoryTables.Load(category); ok {
+		return v.(*RuneTable)
+	}
+	rangeTable, ok := unicode.Categories[category]
+	if !ok {
+		return nil
+	}
+	var ranges [][2]rune
+	for _, r := range rangeTable.R16 {
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			if r.Stride == 1 {
+				ranges = append(ranges, [2]rune{c, rune(r.Hi)})
+				break
+			}
+			ranges = append(ranges, [2]rune{c, c})
+		}
+	}
+	for _, r := range rangeTable.R32 {
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			if r.Stride == 1 {
+				ranges = append(ranges, [2]rune{c, rune(r.Hi)})
+				break
+			}
+			ranges = append(ranges, [2]rune{c, c})
+		}
+	}
+	t := NewRuneTable(ranges)
+	categoryTables.Store(category, t)
+	return t
+}
+
+// runeTableOf returns the table of runes matched by the rune instruction
+func runeTableOf(inst *Instruction) *RuneTable {
+	t := inst.Table
+	if t == nil {
+		if len(inst.Runes) > 0 {
+			var ranges [][2]rune
+			for _, r := range inst.Runes {
+				ranges = append(ranges, [2]rune{r, r})
+			}
+			t = NewRuneTable(ranges)
+		} else if inst.RuneRange[0] != inst.RuneRange[1] {
+			t = NewRuneTable([][2]rune{inst.RuneRange})
+		} else if inst.Category != "" {
+			t = categoryTable(inst.Category)
+			if t == nil {
+				t = NewRuneTable(nil)
+			}
+		} else {
+			t = NewRuneTable([][2]rune{{inst.Rune, inst.Rune}})
+		}
+	}
+	if inst.Inverse {
+		t = t.complement()
+	}
+	return t
+}
+
+// tableOnly reports whether the rune instruction is defined by Table only, like instructions of RuneClass
+func tableOnly(inst *Instruction) bool {
+	return inst.Table != nil && len(inst.Runes) == 0 && inst.RuneRange[0] == inst.RuneRange[1] && inst.Category == ""
+}
@@ -0,0 +1,98 @@
+package pav
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestRuneTable(t *testing.T) {
+	for _, category := range []string{"L", "Lu", "Nd", "Zs", "So"} {
+		table := categoryTable(category)
+		for r := rune(0); r <= unicode.MaxRune; r++ {
+			if table.Contains(r) != unicode.Is(unicode.Categories[category], r) {
+				t.Fatalf("%s %q", category, r)
+			}
+		}
+	}
+	eq(t,
+		categoryTable("foo") == nil, true,
+	)
+
+	table := NewRuneTable([][2]rune{{'c', 'a'}, {'x', 'z'}, {'a', 'b'}, {'c', 'c'}, {'é', 'é'}, {0x10000, 0x10001}})
+	eq(t,
+		len(table.Ranges), 4,
+		table.Contains('a'), true,
+		table.Contains('c'), true,
+		table.Contains('d'), false,
+		table.Contains('é'), true,
+		table.Contains('è'), false,
+		table.Contains(0x10001), true,
+		table.Contains(-1), false,
+		table.complement().Contains('d'), true,
+		table.complement().Contains('y'), false,
+		table.containsTable(NewRuneTable([][2]rune{{'a', 'b'}, {'y', 'z'}})), true,
+		table.containsTable(NewRuneTable([][2]rune{{'a', 'd'}})), false,
+	)
+}
+
+func TestRuneClass(t *testing.T) {
+	inst := RuneClass(
+		RuneRange('a', 'c'),
+		RuneSet('x', 'y'),
+		Rune('_'),
+		RuneCategory("Nd"),
+		RuneInverse(RuneRange(0, 'é')),
+	)
+	for r, ok := range map[rune]bool{
+		'a': true,
+		'c': true,
+		'd': false,
+		'y': true,
+		'_': true,
+		'0': true,
+		'٣': true,
+		'é': false,
+		'ê': true,
+		'-': false,
+	} {
+		eq(t,
+			matchRune(inst, r), ok,
+			matchRune(RuneInverse(inst), r), !ok,
+		)
+	}
+	eq(t,
+		matchRune(RuneClass(), 'a'), false,
+		runeClass(RuneClass(RuneRange('a', 'c'), Rune('_'), RuneSet('x', 'y'))), "[_a-cxy]",
+		runeClass(RuneInverse(RuneClass(Rune('a')))), "[^a]",
+		RuneClass(RuneRange('a', 'c'), Rune('x')).String()[:len("OpRune ['a'-'c' 'x']")], "OpRune ['a'-'c' 'x']",
+	)
+
+	// compiled classes
+	re, err := CompileRegexp(`[a-z_]+[^0-9]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eq(t,
+		match(NewVM(nil, re), "ab_-"), true,
+		match(NewVM(nil, re), "ab_9"), false,
+	)
+}
+
+func BenchmarkMatchRune(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		inst *Instruction
+	}{
+		{"set", RuneSet([]rune("abcdefghijklmnopqrstuvwxyz")...)},
+		{"set-scan", &Instruction{Op: OpRune, Runes: []rune("abcdefghijklmnopqrstuvwxyz")}},
+		{"category", RuneCategory("L")},
+		{"category-unicode", &Instruction{Op: OpRune, Category: "L"}},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				matchRune(c.inst, 'z')
+				matchRune(c.inst, 'ж')
+			}
+		})
+	}
+}
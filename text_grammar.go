@@ -0,0 +1,455 @@
+package pav
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TextGrammarParser is the grammar of the text notation accepted by CompileTextGrammar
+type TextGrammarParser struct{}
+
+func (_ TextGrammarParser) Token(str string) *Instruction {
+	return Seq(
+		Literal(str),
+		Named("Spacing"),
+	)
+}
+
+func (_ TextGrammarParser) Grammar() *Instruction {
+	return Seq(
+		Named("Spacing"),
+		OneOrMore(
+			Named("Definition"),
+		),
+	)
+}
+
+func (p TextGrammarParser) Definition() *Instruction {
+	return Seq(
+		Named("Identifier"),
+		p.Token("<-"),
+		Named("Expression"),
+	)
+}
+
+func (_ TextGrammarParser) Expression() *Instruction {
+	return Seq(
+		Named("Sequence"),
+		ZeroOrMore(
+			Seq(
+				RuneSet('/', '|'),
+				Named("Spacing"),
+				Named("Sequence"),
+			),
+		),
+	)
+}
+
+func (_ TextGrammarParser) Sequence() *Instruction {
+	return OneOrMore(
+		Named("Prefix"),
+	)
+}
+
+func (_ TextGrammarParser) Prefix() *Instruction {
+	return Seq(
+		Optional(
+			Named("Predicate"),
+		),
+		Named("Suffix"),
+	)
+}
+
+func (_ TextGrammarParser) Predicate() *Instruction {
+	return Seq(
+		RuneSet('&', '!'),
+		Named("Spacing"),
+	)
+}
+
+func (_ TextGrammarParser) Suffix() *Instruction {
+	return Seq(
+		Named("Primary"),
+		Optional(
+			Named("Quantifier"),
+		),
+	)
+}
+
+func (_ TextGrammarParser) Quantifier() *Instruction {
+	return Seq(
+		RuneSet('?', '*', '+'),
+		Named("Spacing"),
+	)
+}
+
+func (p TextGrammarParser) Primary() *Instruction {
+	return Longest(
+		Named("Identifier"),
+		Seq(
+			p.Token("("),
+			Named("Expression"),
+			p.Token(")"),
+		),
+		Named("Literal"),
+		Named("Class"),
+		Named("Dot"),
+	)
+}
+
+func (_ TextGrammarParser) Identifier() *Instruction {
+	return Seq(
+		Named("Name"),
+		Named("Spacing"),
+	)
+}
+
+func (_ TextGrammarParser) Name() *Instruction {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz_"
+	return Seq(
+		RuneSet([]rune(letters)...),
+		ZeroOrMore(
+			RuneSet([]rune(letters+"0123456789")...),
+		),
+		// the longest name only
+		RunePredict(
+			RuneInverse(RuneSet([]rune(letters+"0123456789")...)),
+			nil,
+		),
+	)
+}
+
+func (_ TextGrammarParser) Literal() *Instruction {
+	return Seq(
+		Longest(
+			Seq(
+				Rune('\''),
+				ZeroOrMore(
+					RunePredict(
+						RuneInverse(Rune('\'')),
+						Named("Char"),
+					),
+				),
+				Rune('\''),
+			),
+			Seq(
+				Rune('"'),
+				ZeroOrMore(
+					RunePredict(
+						RuneInverse(Rune('"')),
+						Named("Char"),
+					),
+				),
+				Rune('"'),
+			),
+		),
+		Named("Spacing"),
+	)
+}
+
+func (_ TextGrammarParser) Class() *Instruction {
+	return Seq(
+		Rune('['),
+		Optional(
+			Longest(
+				Seq(
+					Named("Negate"),
+					Optional(
+						Named("ClassBody"),
+					),
+				),
+				RunePredict(
+					RuneInverse(RuneSet('^', ']')),
+					Named("ClassBody"),
+				),
+			),
+		),
+		Rune(']'),
+		Named("Spacing"),
+	)
+}
+
+func (_ TextGrammarParser) ClassBody() *Instruction {
+	return Longest(
+		Seq(
+			Named("Dash"),
+			ZeroOrMore(
+				Named("Range"),
+			),
+		),
+		Seq(
+			OneOrMore(
+				Named("Range"),
+			),
+			Optional(
+				Named("Dash"),
+			),
+		),
+	)
+}
+
+func (_ TextGrammarParser) Negate() *Instruction {
+	return Rune('^')
+}
+
+func (_ TextGrammarParser) Dash() *Instruction {
+	return Rune('-')
+}
+
+func (_ TextGrammarParser) Range() *Instruction {
+	return Seq(
+		Named("ClassChar"),
+		Optional(
+			Seq(
+				Rune('-'),
+				Named("ClassChar"),
+			),
+		),
+	)
+}
+
+func (_ TextGrammarParser) ClassChar() *Instruction {
+	return RunePredict(
+		RuneInverse(RuneSet(']', '-')),
+		Named("Char"),
+	)
+}
+
+func (_ TextGrammarParser) Char() *Instruction {
+	return Longest(
+		Seq(
+			Rune('\\'),
+			Longest(
+				RuneSet('n', 'r', 't', '\'', '"', '[', ']', '\\', '-'),
+				Seq(
+					Rune('u'),
+					Named("HexDigit"),
+					Named("HexDigit"),
+					Named("HexDigit"),
+					Named("HexDigit"),
+				),
+			),
+		),
+		RuneInverse(RuneSet('\\', '\n')),
+	)
+}
+
+func (_ TextGrammarParser) HexDigit() *Instruction {
+	return First(
+		RuneRange('0', '9'),
+		RuneRange('a', 'f'),
+		RuneRange('A', 'F'),
+	)
+}
+
+func (p TextGrammarParser) Dot() *Instruction {
+	return p.Token(".")
+}
+
+func (_ TextGrammarParser) Spacing() *Instruction {
+	return ZeroOrMore(
+		Longest(
+			RuneSet(' ', '\t', '\r', '\n'),
+			Seq(
+				Rune('#'),
+				ZeroOrMore(
+					RuneInverse(Rune('\n')),
+				),
+				Rune('\n'),
+			),
+		),
+	)
+}
+
+// CompileTextGrammar compiles grammar definitions like
+//
+//	Value <- Blank (String | Number | Object)
+//
+// to routines. The notation borrows the syntax of PEG but not its semantics.
+// Alternatives separated by "|" compile to Longest: all alternatives are tried,
+// and the input matches if any of them leads to a match.
+// The ordered choice "/" is rejected, since grammars relying on it would match differently.
+// Suffixes "?", "*", "+" compile to Optional, ZeroOrMore, OneOrMore, which are not possessive.
+// Literals are quoted by ' or ", classes like [a-z] or [^"\\] match one rune, "." matches any rune.
+// Predicates are not supported.
+func CompileTextGrammar(src string) (map[string]Routine, error) {
+	runes, grammar, err := parseWithCapture(new(TextGrammarParser), "Grammar", src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &textGrammarCompiler{
+		src:      runes,
+		routines: make(map[string]Routine),
+	}
+	for _, def := range grammar.Children {
+		if def.Name != "Definition" {
+			continue
+		}
+		name := c.text(def.Find("Identifier").Find("Name"))
+		if _, ok := c.routines[name]; ok {
+			return nil, fmt.Errorf("%s: duplicated definition of %s", runePos(runes, def.Start), name)
+		}
+		inst, err := c.expression(def.Find("Expression"))
+		if err != nil {
+			return nil, err
+		}
+		c.routines[name] = Routine{
+			Start: inst,
+		}
+	}
+	for _, ref := range c.refs {
+		if _, ok := c.routines[c.text(ref)]; !ok {
+			return nil, fmt.Errorf("%s: undefined rule %s", runePos(runes, ref.Start), c.text(ref))
+		}
+	}
+
+	return c.routines, nil
+}
+
+type textGrammarCompiler struct {
+	src      []rune
+	routines map[string]Routine
+	refs     []*Node
+}
+
+func (c *textGrammarCompiler) text(n *Node) string {
+	return string(c.src[n.Start:n.End])
+}
+
+func (c *textGrammarCompiler) expression(n *Node) (*Instruction, error) {
+	var insts []*Instruction
+	pos := n.Start
+	for _, child := range n.Children {
+		if child.Name != "Sequence" {
+			continue
+		}
+		// the separator is between sequences
+		for i := pos; i < child.Start; i++ {
+			if c.src[i] == '/' {
+				return nil, fmt.Errorf("%s: ordered choice not supported, use |", runePos(c.src, i))
+			}
+		}
+		pos = child.End
+		inst, err := c.sequence(child)
+		if err != nil {
+			return nil, err
+		}
+		insts = append(insts, inst)
+	}
+	if len(insts) == 1 {
+		return insts[0], nil
+	}
+	return Longest(insts...), nil
+}
+
+func (c *textGrammarCompiler) sequence(n *Node) (*Instruction, error) {
+	var insts []*Instruction
+	for _, prefix := range n.Children {
+		if predicate := prefix.Find("Predicate"); predicate != nil {
+			return nil, fmt.Errorf("%s: predicate not supported", runePos(c.src, predicate.Start))
+		}
+		suffix := prefix.Find("Suffix")
+		inst, err := c.primary(suffix.Find("Primary"))
+		if err != nil {
+			return nil, err
+		}
+		if quantifier := suffix.Find("Quantifier"); quantifier != nil {
+			switch c.src[quantifier.Start] {
+			case '?':
+				inst = Optional(inst)
+			case '*':
+				inst = ZeroOrMore(inst)
+			case '+':
+				inst = OneOrMore(inst)
+			}
+		}
+		insts = append(insts, inst)
+	}
+	if len(insts) == 1 {
+		return insts[0], nil
+	}
+	return Seq(insts...), nil
+}
+
+func (c *textGrammarCompiler) primary(n *Node) (*Instruction, error) {
+	for _, child := range n.Children {
+		switch child.Name {
+
+		case "Identifier":
+			name := child.Find("Name")
+			c.refs = append(c.refs, name)
+			return Named(c.text(name)), nil
+
+		case "Expression":
+			return c.expression(child)
+
+		case "Literal":
+			var runes []rune
+			for _, char := range child.Children {
+				if char.Name == "Char" {
+					runes = append(runes, c.char(char))
+				}
+			}
+			if len(runes) == 0 {
+				return nil, fmt.Errorf("%s: empty literal", runePos(c.src, child.Start))
+			}
+			return RuneSeq(runes), nil
+
+		case "Class":
+			var ranges [][2]rune
+			body := child.Find("ClassBody")
+			if body == nil {
+				body = new(Node)
+			}
+			for _, r := range body.Children {
+				switch r.Name {
+				case "Dash":
+					ranges = append(ranges, [2]rune{'-', '-'})
+				case "Range":
+					var chars []rune
+					for _, classChar := range r.Children {
+						if classChar.Name == "ClassChar" {
+							chars = append(chars, c.char(classChar.Find("Char")))
+						}
+					}
+					if len(chars) == 1 {
+						chars = append(chars, chars[0])
+					}
+					if chars[0] > chars[1] {
+						return nil, fmt.Errorf("%s: bad range", runePos(c.src, r.Start))
+					}
+					ranges = append(ranges, [2]rune{chars[0], chars[1]})
+				}
+			}
+			return runeRanges(ranges, child.Find("Negate") != nil), nil
+
+		case "Dot":
+			return AnyRune(), nil
+
+		}
+	}
+	panic("bad tree") // NOCOVER
+}
+
+func (c *textGrammarCompiler) char(n *Node) rune {
+	text := c.src[n.Start:n.End]
+	if text[0] != '\\' {
+		return text[0]
+	}
+	switch text[1] {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'u':
+		r, err := strconv.ParseUint(string(text[2:]), 16, 32)
+		if err != nil { // NOCOVER
+			panic(err)
+		}
+		return rune(r)
+	}
+	return text[1]
+}
@@ -0,0 +1,155 @@
+package pav
+
+import "testing"
+
+func TestTextGrammar(t *testing.T) {
+	routines, err := CompileTextGrammar(`
+# json subset
+Value <- Blank (String | Number | Array)
+Blank <- [ \t\n]*
+String <- '"' (Char | Escape)* '"'
+Char <- [^"\\]
+Escape <- '\\' ["\\nt] | "\\u" Hex Hex Hex Hex
+Hex <- [0-9a-fA-F]
+Number <- '-'? ('0' | [1-9] [0-9]*) ('.' [0-9]+)?
+Array <- Blank '[' (Value (Blank ',' Value)*)? Blank ']'
+Any<-.`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{
+		`"foo"`,
+		` "é\n"`,
+		`42`,
+		`-0.5`,
+		`[]`,
+		`[1, "2", [3]]`,
+	} {
+		vm := NewVM(routines, Named("Value"))
+		if !match(vm, input) {
+			t.Errorf("should match: %s", input)
+		}
+	}
+	for _, input := range []string{
+		`"foo`,
+		`"\x"`,
+		`01`,
+		`[1,]`,
+	} {
+		vm := NewVM(routines, Named("Value"))
+		if match(vm, input) {
+			t.Errorf("should not match: %s", input)
+		}
+	}
+
+	vm := NewVM(routines, Named("Any"))
+	eq(t,
+		match(vm, "我"), true,
+	)
+}
+
+func TestTextGrammarClass(t *testing.T) {
+	routines, err := CompileTextGrammar(`
+A <- [-+]
+B <- [a-]
+C <- [^a-c\]x-z]
+D <- [一-鿿]
+E <- []`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name  string
+		input string
+		ok    bool
+	}{
+		{"A", "-", true},
+		{"A", "+", true},
+		{"A", "a", false},
+		{"B", "a", true},
+		{"B", "-", true},
+		{"B", "b", false},
+		{"C", "d", true},
+		{"C", "b", false},
+		{"C", "]", false},
+		{"C", "y", false},
+		{"D", "中", true},
+		{"D", "a", false},
+		{"E", "a", false},
+	} {
+		vm := NewVM(routines, Named(c.name))
+		if match(vm, c.input) != c.ok {
+			t.Errorf("%s %q", c.name, c.input)
+		}
+	}
+}
+
+func TestTextGrammarAlternatives(t *testing.T) {
+	routines, err := CompileTextGrammar(`
+V <- [0-9]+ | "x"
+W <- ("a" | "ab") "c"
+B <- ('a' | 'ab') 'b'
+Value <- Blank (String | Number | Object)
+Blank <- [ \t\n]*
+String <- '"' [^"]* '"'
+Number <- [0-9]+
+Object <- '{' Blank '}'
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name  string
+		input string
+		ok    bool
+	}{
+		{"V", "12", true},
+		{"V", "x", true},
+		{"V", "1x", false},
+		{"W", "abc", true},
+		{"W", "ac", true},
+		{"W", "abbc", false},
+		{"B", "abb", true},
+		{"B", "ab", true},
+		{"Value", " 42", true},
+		{"Value", `"foo"`, true},
+		{"Value", "{ }", true},
+		{"Value", "4 2", false},
+	} {
+		vm := NewVM(routines, Named(c.name))
+		if match(vm, c.input) != c.ok {
+			t.Errorf("%s %q", c.name, c.input)
+		}
+	}
+}
+
+func TestTextGrammarError(t *testing.T) {
+	for src, msg := range map[string]string{
+		"A <- B":               "1:6: undefined rule B",
+		"A <- 'a'\nA <- 'b'":   "2:1: duplicated definition of A",
+		"A <- 'a'\n  B <- ":    "2:8: unexpected end of input",
+		"A <- 'a' <-":          "1:10: syntax error",
+		"A <- !'a'":            "1:6: predicate not supported",
+		"A <- 'a' / 'b'":       "1:10: ordered choice not supported, use |",
+		"A <- ('a' | 'b'/'c')": "1:16: ordered choice not supported, use |",
+		"A <- ''":              "1:6: empty literal",
+		"A <- [z-a]":           "1:7: bad range",
+		"A <- 'a' # comment":   "",
+		"A <- 'a'\nB <- A A*":  "",
+	} {
+		_, err := CompileTextGrammar(src)
+		if msg == "" {
+			if err != nil {
+				t.Errorf("%q: %v", src, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: should fail", src)
+			continue
+		}
+		eq(t,
+			err.Error(), msg,
+		)
+	}
+}
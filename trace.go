@@ -0,0 +1,84 @@
+package pav
+
+import (
+	"fmt"
+	"io"
+)
+
+type TraceKind uint8
+
+const (
+	// thread created by OpClone, From is the parent thread
+	TraceSpawn TraceKind = iota + 1
+	// named routine called
+	TraceCall
+	// named routine returned
+	TraceReturn
+	// rune accepted by Inst
+	TraceMatch
+	// rune rejected by Inst, the thread is killed
+	TraceReject
+	// thread killed by a shortest cluster completed by From
+	TraceKillCluster
+	// thread killed for exceeding the bound of Inst
+	TraceKillBound
+)
+
+type TraceEvent struct {
+	Kind   TraceKind
+	Step   int
+	Thread *Thread
+	From   *Thread
+	Inst   *Instruction
+	// routine name of TraceCall and TraceReturn
+	Name string
+	// input of TraceMatch and TraceReject
+	Rune rune
+}
+
+// Tracer receives events of VM execution.
+// Threads in events are valid until the next Step or Reset, the VM reuses stopped threads,
+// so a Tracer should copy the fields needed instead of keeping the threads.
+type Tracer interface {
+	Trace(TraceEvent)
+}
+
+type TracerFunc func(TraceEvent)
+
+var _ Tracer = TracerFunc(nil)
+
+func (t TracerFunc) Trace(ev TraceEvent) {
+	t(ev)
+}
+
+// NewLogTracer returns a tracer writing one line per event to w
+func NewLogTracer(w io.Writer) Tracer {
+	return TracerFunc(func(ev TraceEvent) {
+		fmt.Fprintf(w, "%s\n", ev)
+	})
+}
+
+func (e TraceEvent) String() string {
+	s := fmt.Sprintf("step %d thread %d %s", e.Step, e.Thread.ID, e.Kind)
+	switch e.Kind {
+	case TraceSpawn:
+		s += fmt.Sprintf(" from %d", e.From.ID)
+	case TraceCall, TraceReturn:
+		s += " " + e.Name
+	case TraceMatch, TraceReject:
+		s += fmt.Sprintf(" %q by %s", e.Rune, e.Inst)
+	case TraceKillCluster:
+		s += fmt.Sprintf(" by %d", e.From.ID)
+	case TraceKillBound:
+		s += " at " + e.Inst.String()
+	}
+	return s
+}
+
+func (v *VM) trace(ev TraceEvent) {
+	if v.Tracer == nil {
+		return
+	}
+	ev.Step = v.step
+	v.Tracer.Trace(ev)
+}
@@ -0,0 +1,74 @@
+package pav
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	vm := NewVM(map[string]Routine{
+		"A": {
+			Start: Seq(Rune('a'), Named("B")),
+		},
+		"B": {
+			Start: First(Rune('b'), Literal("bc"), Rune('c')),
+		},
+	}, Named("A"))
+	buf := new(bytes.Buffer)
+	vm.Tracer = NewLogTracer(buf)
+	if !match(vm, "ab") {
+		t.Fatal("should match")
+	}
+	eq(t,
+		strings.Split(strings.TrimSpace(buf.String()), "\n"), []string{
+			"step 0 thread 0 TraceCall A",
+			"step 0 thread 0 TraceMatch 'a' by OpRune 'a' at trace_test.go:12",
+			"step 1 thread 0 TraceCall B",
+			"step 1 thread 1 TraceSpawn from 0",
+			"step 1 thread 2 TraceSpawn from 0",
+			"step 1 thread 0 TraceMatch 'b' by OpRune 'b' at trace_test.go:15",
+			"step 1 thread 1 TraceMatch 'b' by OpRune 'b' at trace_test.go:15",
+			"step 1 thread 2 TraceReject 'b' by OpRune 'c' at trace_test.go:15",
+			"step 2 thread 1 TraceKillCluster by 0",
+			"step 2 thread 0 TraceReturn B",
+			"step 2 thread 0 TraceReturn A",
+		},
+	)
+}
+
+func TestTracerBound(t *testing.T) {
+	vm := NewVM(map[string]Routine{
+		"A": {
+			Start: Longest(
+				Seq(Named("A"), Rune('a')),
+				Rune('a'),
+			),
+		},
+	}, Named("A"))
+	kinds := make(map[TraceKind]int)
+	vm.Tracer = TracerFunc(func(ev TraceEvent) {
+		kinds[ev.Kind]++
+	})
+	if !match(vm, "aaa") {
+		t.Fatal("should match")
+	}
+	if kinds[TraceKillBound] == 0 {
+		t.Fatal("expecting bound kills")
+	}
+}
+
+func TestTracerTransparent(t *testing.T) {
+	for _, input := range []string{
+		`{"a": [1, 2, {"b": null}]}`,
+		`[1, 2,]`,
+	} {
+		vm := NewVMFromObject(new(JSONParser), Named("Text"))
+		vm.Tracer = TracerFunc(func(TraceEvent) {})
+		traced := match(vm, input)
+		vm = NewVMFromObject(new(JSONParser), Named("Text"))
+		eq(t,
+			traced, match(vm, input),
+		)
+	}
+}
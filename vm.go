@@ -2,6 +2,7 @@ package pav
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"unicode"
@@ -10,26 +11,66 @@ import (
 type VM struct {
 	Routines map[string]Routine
 	Threads  []*Thread
-	step     int
+	// record spans of named routines in Thread.Captures
+	Capture bool
+	Tracer  Tracer
+	step    int
+	// last assigned Thread.ID
+	threadID int
+	// the upcoming rune for pruning branches in StepPeek
+	peeking bool
+	peek    rune
+	peekEOF bool
+	// analysis of Routines for pruning
+	analysis *Analysis
+	// analysis is shared by VMs of a Program and not modified
+	sharedAnalysis bool
+	// threads for reusing
+	free []*Thread
+	// buffers of StepResult
+	matched []*Thread
+	failed  []*Thread
+	// calls to branches of OpClone instructions
+	branchCalls map[*Instruction][]*Instruction
+	// frames for reusing
+	frames []*Frame
+	// bound counters of the current step, the first one is unused
+	stats []instStat
 }
 
 type Thread struct {
-	Stack     []Frame
-	PC        *Instruction
-	Match     bool
-	instStats []instStat
+	ID int
+	// top frame of the call stack, shared by forked threads
+	Stack    *Frame
+	PC       *Instruction
+	Match    bool
+	Captures *Capture
+	// index of the latest bound counter in VM.stats, 0 for none
+	stats int
 }
 
+// instStat counts visits of the instruction in the current step.
+// Counters are immutable linked nodes shared by forked threads, incrementing pushes a new node.
 type instStat struct {
 	Inst    *Instruction
 	Counter int
-	Bound   int
+	// index of the next node, 0 for none
+	next int
 }
 
+// bound of visits of an instruction in a step
+const instBound = 64
+
 type Frame struct {
-	Return      *Instruction
-	ClusterID   int64
+	Return *Instruction
+	// clone instruction of the cluster, nil if not clustered
+	Cluster     *Instruction
 	ClusterType ClusterType
+	Name        string
+	// the frame below, frames are immutable and shared by forked threads
+	Parent *Frame
+	// number of threads and frames referencing the frame
+	refs int
 }
 
 type Routine struct {
@@ -41,8 +82,11 @@ type Instruction struct {
 	Op   Op
 
 	// OpCall
-	Name        string
-	ClusterID   int64
+	Name string
+	// clone instruction of the cluster the call frame belongs to
+	Cluster *Instruction
+
+	// OpClone with clustered branches, OpCall
 	ClusterType ClusterType
 
 	// OpJump, OpCall, OpRune(Predict=true)
@@ -56,11 +100,23 @@ type Instruction struct {
 	Runes     []rune
 	RuneRange [2]rune
 	Category  string
-	Inverse   bool
-	Predict   bool
+	// runes of Runes, Category or RuneClass for fast matching, takes precedence over them
+	Table   *RuneTable
+	Inverse bool
+	Predict bool
 
 	// OpIndirect
 	InstP **Instruction
+
+	// constructor of the instruction and its instruction arguments
+	Combinator Combinator
+	Operands   []*Instruction
+
+	// caller of the constructor
+	file string
+	line int
+	// created by the vm, not counted for bounds
+	synthetic bool
 }
 
 type Op uint8
@@ -74,6 +130,32 @@ const (
 	OpIndirect
 )
 
+type Combinator uint8
+
+const (
+	CombLiteral Combinator = iota + 1
+	CombRuneSeq
+	CombRuneSet
+	CombRuneRange
+	CombRune
+	CombAnyRune
+	CombRuneInverse
+	CombSeq
+	CombShortest
+	CombFirst
+	CombLongest
+	CombOptional
+	CombZeroOrMore
+	CombOneOrMore
+	CombIndirect
+	CombNamed
+	CombRuneCategory
+	CombRunePredict
+	CombKeywords
+	CombLongestLiteral
+	CombRuneClass
+)
+
 type ClusterType uint8
 
 const (
@@ -81,19 +163,28 @@ const (
 	ClusterBound
 )
 
+// StepResult holds threads stopped in a step.
+// It is valid until the next Step or Reset: the slices are reused by the next Step,
+// and threads in Failed are reused by the next Step or Reset, so their fields are overwritten.
+// Threads in Matched are not reused.
+// Copy the fields needed, such as Captures, instead of keeping the threads.
 type StepResult struct {
 	Matched []*Thread
 	Failed  []*Thread
 }
 
+// implicitReturn is the instruction of nil PC
+var implicitReturn = &Instruction{
+	Op:        OpReturn,
+	synthetic: true,
+}
+
 func (v *VM) prepareToFeed(thread *Thread) {
 	for {
 
 		// implicit return
 		if thread.PC == nil {
-			thread.PC = &Instruction{
-				Op: OpReturn,
-			}
+			thread.PC = implicitReturn
 		}
 
 		// ready to feed
@@ -103,44 +194,77 @@ func (v *VM) prepareToFeed(thread *Thread) {
 
 		//TODO restart with larger bound
 		// update counter
-		added := false
-		for i, c := range thread.instStats {
-			if c.Inst == thread.PC {
-				if c.Counter >= c.Bound {
-					v.kill(thread)
-					return
+		if !thread.PC.synthetic {
+			counter := 0
+			for i := thread.stats; i > 0; i = v.stats[i].next {
+				if v.stats[i].Inst == thread.PC {
+					counter = v.stats[i].Counter + 1
+					break
 				}
-				thread.instStats[i].Counter++
-				added = true
-				break
 			}
-		}
-		if !added {
-			thread.instStats = append(thread.instStats, instStat{
+			if counter > instBound {
+				v.trace(TraceEvent{
+					Kind:   TraceKillBound,
+					Thread: thread,
+					Inst:   thread.PC,
+				})
+				v.kill(thread)
+				return
+			}
+			if len(v.stats) == 0 {
+				v.stats = append(v.stats, instStat{})
+			}
+			v.stats = append(v.stats, instStat{
 				Inst:    thread.PC,
-				Counter: 0,
-				Bound:   64,
+				Counter: counter,
+				next:    thread.stats,
 			})
+			thread.stats = len(v.stats) - 1
 		}
 
 		switch thread.PC.Op {
 
 		case OpCall:
-			thread.Stack = append(thread.Stack, Frame{
-				Return:      thread.PC.Next,
-				ClusterID:   thread.PC.ClusterID,
-				ClusterType: thread.PC.ClusterType,
-			})
+			named := thread.PC.Inst == nil && thread.PC.Name != ""
+			capture := v.Capture && named
+			// frames of named calls are kept for captures and tracing returns
+			keepName := named && (v.Capture || v.Tracer != nil)
+			// tail call: a frame returning to nothing only unwinds to the frame below it
+			if thread.PC.Next != nil || thread.PC.Cluster != nil || keepName {
+				frame := Frame{
+					Return:      thread.PC.Next,
+					Cluster:     thread.PC.Cluster,
+					ClusterType: thread.PC.ClusterType,
+				}
+				if keepName {
+					frame.Name = thread.PC.Name
+				}
+				v.pushFrame(thread, frame)
+			}
 			if thread.PC.Inst != nil {
 				thread.PC = thread.PC.Inst
 			} else if thread.PC.Name != "" {
 				r, ok := v.Routines[thread.PC.Name]
 				if !ok {
-					panic(fmt.Errorf("no such name: %s", thread.PC.Name))
+					panic(fmt.Errorf("no such name: %s at %s", thread.PC.Name, thread.PC.Pos()))
+				}
+				if capture {
+					thread.Captures = &Capture{
+						Prev:  thread.Captures,
+						Name:  thread.PC.Name,
+						Pos:   v.step,
+						Start: true,
+					}
 				}
+				v.trace(TraceEvent{
+					Kind:   TraceCall,
+					Thread: thread,
+					Inst:   thread.PC,
+					Name:   thread.PC.Name,
+				})
 				thread.PC = r.Start
 			} else { // NOCOVER
-				panic(fmt.Errorf("bad instruction: %+v", thread.PC))
+				panic(fmt.Errorf("bad instruction: %s", thread.PC))
 			}
 
 		case OpJump:
@@ -148,39 +272,83 @@ func (v *VM) prepareToFeed(thread *Thread) {
 
 		case OpClone:
 			inst := thread.PC
-			for i, start := range thread.PC.Insts {
+			calls, ok := v.branchCalls[inst]
+			if !ok {
+				calls = make([]*Instruction, len(inst.Insts))
+				var cluster *Instruction
+				if inst.ClusterType != 0 {
+					cluster = inst
+				}
+				for i, start := range inst.Insts {
+					if start != nil {
+						calls[i] = &Instruction{
+							Op:          OpCall,
+							Inst:        start,
+							Cluster:     cluster,
+							ClusterType: inst.ClusterType,
+							Next:        inst.Next,
+							synthetic:   true,
+						}
+					}
+				}
+				if v.branchCalls == nil {
+					v.branchCalls = make(map[*Instruction][]*Instruction)
+				}
+				v.branchCalls[inst] = calls
+			}
+			spawned := 0
+			for i, start := range inst.Insts {
+				if start != nil && v.peeking && !v.mayAccept(start) {
+					// the branch thread will fail at the upcoming rune
+					continue
+				}
 				var t *Thread
-				if i == 0 {
+				if spawned == 0 {
 					// use current thread
 					t = thread
 				} else {
 					// create new thread
-					stack := make([]Frame, len(thread.Stack))
-					copy(stack, thread.Stack)
-					counters := make([]instStat, len(thread.instStats))
-					copy(counters, thread.instStats)
-					t = &Thread{
-						Stack:     stack,
-						instStats: counters,
+					t = v.newThread()
+					v.threadID++
+					t.ID = v.threadID
+					t.Stack = thread.Stack
+					if t.Stack != nil {
+						t.Stack.refs++
 					}
+					t.Captures = thread.Captures
+					t.stats = thread.stats
+					// a branch returning without consuming runes matches as the current thread
+					t.Match = thread.Match
 					v.Threads = append(v.Threads, t)
+					v.trace(TraceEvent{
+						Kind:   TraceSpawn,
+						Thread: t,
+						From:   thread,
+						Inst:   inst,
+					})
 				}
+				spawned++
 				// set pc
 				if start == nil {
 					t.PC = inst.Next
 				} else {
-					t.PC = &Instruction{
-						Op:          OpCall,
-						Inst:        start,
-						ClusterID:   inst.ClusterID,
-						ClusterType: inst.ClusterType,
-						Next:        inst.Next,
-					}
+					t.PC = calls[i]
 				}
 			}
+			if spawned == 0 {
+				v.kill(thread)
+				return
+			}
 
 		case OpReturn:
-			if len(thread.Stack) > 0 {
+			if thread.Stack != nil {
+				if name := thread.Stack.Name; name != "" {
+					v.trace(TraceEvent{
+						Kind:   TraceReturn,
+						Thread: thread,
+						Name:   name,
+					})
+				}
 				v.unwindStack(thread)
 			} else {
 				thread.PC = nil
@@ -196,13 +364,53 @@ func (v *VM) prepareToFeed(thread *Thread) {
 
 }
 
+func (v *VM) pushFrame(thread *Thread, frame Frame) {
+	var f *Frame
+	if n := len(v.frames); n > 0 {
+		f = v.frames[n-1]
+		v.frames = v.frames[:n-1]
+	} else {
+		f = new(Frame)
+	}
+	*f = frame
+	// the reference of the thread is moved to the new frame
+	f.Parent = thread.Stack
+	f.refs = 1
+	thread.Stack = f
+}
+
+// popFrame removes the top frame of the thread and returns a copy of it
+func (v *VM) popFrame(thread *Thread) Frame {
+	f := thread.Stack
+	frame := *f
+	thread.Stack = f.Parent
+	if f.refs == 1 {
+		// the reference to the parent is moved to the thread
+		f.Parent = nil
+		v.frames = append(v.frames, f)
+	} else {
+		f.refs--
+		if f.Parent != nil {
+			f.Parent.refs++
+		}
+	}
+	return frame
+}
+
 func (v *VM) unwindStack(thread *Thread) {
-	frame := thread.Stack[len(thread.Stack)-1]
+	frame := v.popFrame(thread)
 	thread.PC = frame.Return
-	thread.Stack = thread.Stack[:len(thread.Stack)-1]
+
+	if frame.Name != "" && v.Capture {
+		thread.Captures = &Capture{
+			Prev: thread.Captures,
+			Name: frame.Name,
+			Pos:  v.step,
+		}
+	}
 
 	// clustered frames
-	if frame.ClusterID > 0 {
+	if frame.Cluster != nil {
 		switch frame.ClusterType {
 
 		case ClusterShortest:
@@ -213,8 +421,13 @@ func (v *VM) unwindStack(thread *Thread) {
 					if t == thread {
 						continue
 					}
-					for _, f := range t.Stack {
-						if f.ClusterID == frame.ClusterID {
+					for f := t.Stack; f != nil; f = f.Parent {
+						if f.Cluster == frame.Cluster {
+							v.trace(TraceEvent{
+								Kind:   TraceKillCluster,
+								Thread: t,
+								From:   thread,
+							})
 							v.kill(t)
 							continue loop_thread
 						}
@@ -230,12 +443,35 @@ func (v *VM) unwindStack(thread *Thread) {
 func (v *VM) Step(input rune) (
 	result StepResult,
 ) {
+	return v.feed(input, false, 0, false)
+}
+
+// StepPeek is Step with the rune after input known, hasNext is false if input is the last rune.
+// Branches of OpClone that can not accept the upcoming rune are not spawned, so their threads are not in StepResult.Failed.
+func (v *VM) StepPeek(input rune, next rune, hasNext bool) (
+	result StepResult,
+) {
+	return v.feed(input, true, next, hasNext)
+}
+
+func (v *VM) feed(input rune, peek bool, next rune, hasNext bool) (
+	result StepResult,
+) {
+	v.peeking = peek
+	v.peek = input
+	v.peekEOF = false
+	v.recycle(v.failed)
 
 	for i := 0; i < len(v.Threads); i++ {
 		v.prepareToFeed(v.Threads[i])
 	}
 
-	for _, thread := range v.Threads {
+	for i := 0; i < len(v.Threads); i++ {
+		thread := v.Threads[i]
+		if thread.PC != nil && thread.PC.Op != OpRune {
+			// added by predicting instructions
+			v.prepareToFeed(thread)
+		}
 	feed:
 		// feed rune
 		if thread.PC != nil {
@@ -245,30 +481,15 @@ func (v *VM) Step(input rune) (
 
 			inst := thread.PC
 
-			if len(inst.Runes) > 0 {
-				// runes
-				thread.Match = false
-				for _, r := range inst.Runes {
-					if input == r {
-						thread.Match = true
-						break
-					}
-				}
-			} else if inst.RuneRange[0] != inst.RuneRange[1] {
-				// rune range
-				thread.Match = input >= inst.RuneRange[0] &&
-					input <= inst.RuneRange[1]
-			} else if inst.Category != "" {
-				thread.Match = unicode.Is(unicode.Categories[inst.Category], input)
-			} else {
-				// single rune
-				thread.Match = input == inst.Rune
-			}
-			if inst.Inverse {
-				thread.Match = !thread.Match
-			}
+			thread.Match = matchRune(inst, input)
 
 			if thread.Match {
+				v.trace(TraceEvent{
+					Kind:   TraceMatch,
+					Thread: thread,
+					Inst:   inst,
+					Rune:   input,
+				})
 				if thread.PC.Predict {
 					thread.PC = thread.PC.Inst
 					v.prepareToFeed(thread)
@@ -277,47 +498,170 @@ func (v *VM) Step(input rune) (
 					thread.PC = thread.PC.Next
 				}
 			} else {
+				v.trace(TraceEvent{
+					Kind:   TraceReject,
+					Thread: thread,
+					Inst:   inst,
+					Rune:   input,
+				})
 				v.kill(thread)
 			}
 
 		}
 
-		thread.instStats = thread.instStats[:0]
+		thread.stats = 0
 	}
+	// no thread references the counters
+	v.stats = v.stats[:0]
 
 	v.step++
+	v.peek = next
+	v.peekEOF = !hasNext
 
 	for i := 0; i < len(v.Threads); i++ {
 		v.prepareToFeed(v.Threads[i])
 	}
 
 	// purge stopped threads
-	i := 0
-	for i < len(v.Threads) {
-		thread := v.Threads[i]
+	result.Matched = v.matched[:0]
+	result.Failed = v.failed[:0]
+	n := 0
+	for _, thread := range v.Threads {
 		if thread.PC == nil {
 			if thread.Match {
-				result.Matched = append(result.Matched, v.Threads[i])
+				result.Matched = append(result.Matched, thread)
 			} else {
-				result.Failed = append(result.Failed, v.Threads[i])
+				result.Failed = append(result.Failed, thread)
 			}
-			v.Threads = append(v.Threads[:i], v.Threads[i+1:]...)
 			continue
 		}
-		i++
+		v.Threads[n] = thread
+		n++
 	}
+	for i := n; i < len(v.Threads); i++ {
+		v.Threads[i] = nil
+	}
+	v.Threads = v.Threads[:n]
+	v.matched = result.Matched
+	v.failed = result.Failed
 
 	return
 }
 
+func (v *VM) newThread() *Thread {
+	if n := len(v.free); n > 0 {
+		t := v.free[n-1]
+		v.free[n-1] = nil
+		v.free = v.free[:n-1]
+		return t
+	}
+	return new(Thread)
+}
+
+// recycle puts the threads to the free list and clears the slice
+func (v *VM) recycle(threads []*Thread) {
+	for i, t := range threads {
+		v.releaseStack(t)
+		t.PC = nil
+		t.Match = false
+		t.Captures = nil
+		t.stats = 0
+		v.free = append(v.free, t)
+		threads[i] = nil
+	}
+}
+
+// mayAccept reports whether a thread starting at the instruction may survive feeding the upcoming rune
+func (v *VM) mayAccept(inst *Instruction) bool {
+	analysis := v.analysisOf(inst)
+	if v.peekEOF {
+		return analysis.Nullable(inst)
+	}
+	return analysis.mayStart(inst, v.peek)
+}
+
+// analysisOf returns the analysis for querying the instruction
+func (v *VM) analysisOf(inst *Instruction) *Analysis {
+	if v.analysis == nil {
+		v.analysis = Analyze(v.Routines)
+	} else if v.sharedAnalysis && inst != nil {
+		if _, ok := v.analysis.infos[inst]; !ok {
+			// instructions not in the program are analyzed by the VM's own analysis
+			v.analysis = Analyze(v.Routines)
+			v.sharedAnalysis = false
+		}
+	}
+	return v.analysis
+}
+
+func matchRune(inst *Instruction, input rune) bool {
+	var match bool
+	if inst.Table != nil {
+		match = inst.Table.Contains(input)
+	} else if len(inst.Runes) > 0 {
+		// runes
+		for _, r := range inst.Runes {
+			if input == r {
+				match = true
+				break
+			}
+		}
+	} else if inst.RuneRange[0] != inst.RuneRange[1] {
+		// rune range
+		match = input >= inst.RuneRange[0] &&
+			input <= inst.RuneRange[1]
+	} else if inst.Category != "" {
+		match = unicode.Is(unicode.Categories[inst.Category], input)
+	} else {
+		// single rune
+		match = input == inst.Rune
+	}
+	if inst.Inverse {
+		match = !match
+	}
+	return match
+}
+
+func (v *VM) Reset(initInst *Instruction) {
+	v.recycle(v.failed)
+	v.failed = v.failed[:0]
+	v.recycle(v.Threads)
+	t := v.newThread()
+	t.ID = 0
+	t.PC = initInst
+	v.Threads = append(v.Threads[:0], t)
+	v.step = 0
+	v.threadID = 0
+}
+
 func (v *VM) kill(t *Thread) {
-	for len(t.Stack) > 0 {
-		v.unwindStack(t)
+	if t.Match || v.Capture {
+		// unwinding records captures and kills Shortest clusters
+		for t.Stack != nil {
+			v.unwindStack(t)
+		}
+	} else {
+		v.releaseStack(t)
 	}
 	t.PC = nil
 	t.Match = false
 }
 
+// releaseStack drops the stack of the thread, frames not referenced by others are reused
+func (v *VM) releaseStack(t *Thread) {
+	for f := t.Stack; f != nil; {
+		f.refs--
+		if f.refs > 0 {
+			break
+		}
+		parent := f.Parent
+		f.Parent = nil
+		v.frames = append(v.frames, f)
+		f = parent
+	}
+	t.Stack = nil
+}
+
 func (v *VM) dumpThreads() { // NOCOVER
 	pt("---- %d threads ----\n", len(v.Threads))
 	for _, thread := range v.Threads { // NOCOVER
@@ -326,27 +670,36 @@ func (v *VM) dumpThreads() { // NOCOVER
 	pt("---- ----\n") // NOCOVER
 }
 
+func NewVM(routines map[string]Routine, initInst *Instruction) *VM {
+	return &VM{
+		Routines: routines,
+		Threads: []*Thread{
+			{
+				PC: initInst,
+			},
+		},
+	}
+}
+
 func NewVMFromObject(obj interface{}, initInst *Instruction) *VM {
+	return NewVM(ObjectRoutines(obj), initInst)
+}
+
+// ObjectRoutines returns routines defined by methods of obj that take no arguments and return *Instruction
+func ObjectRoutines(obj interface{}) map[string]Routine {
 	v := reflect.ValueOf(obj)
 	t := reflect.TypeOf(obj)
-	vm := &VM{
-		Routines: make(map[string]Routine),
-	}
+	routines := make(map[string]Routine)
 	for i := 0; i < v.NumMethod(); i++ {
 		fn := v.Method(i).Interface()
 		if fn, ok := fn.(func() *Instruction); ok {
 			name := t.Method(i).Name
-			vm.Routines[name] = Routine{
+			routines[name] = Routine{
 				Start: fn(),
 			}
 		}
 	}
-	vm.Threads = []*Thread{
-		{
-			PC: initInst,
-		},
-	}
-	return vm
+	return routines
 }
 
 func (i *Instruction) String() string {
@@ -358,7 +711,17 @@ func (i *Instruction) String() string {
 			switch i.Op {
 
 			case OpRune:
-				if len(i.Runes) > 0 {
+				if tableOnly(i) {
+					var ranges []string
+					for _, r := range i.Table.Ranges {
+						if r[0] == r[1] {
+							ranges = append(ranges, fmt.Sprintf("%q", r[0]))
+						} else {
+							ranges = append(ranges, fmt.Sprintf("%q-%q", r[0], r[1]))
+						}
+					}
+					b.WriteString("[" + strings.Join(ranges, " ") + "]")
+				} else if len(i.Runes) > 0 {
 					b.WriteString(fmt.Sprintf("%q", i.Runes))
 				} else if i.RuneRange[0] != i.RuneRange[1] {
 					b.WriteString(fmt.Sprintf("%q - %q", i.RuneRange[0], i.RuneRange[1]))
@@ -371,21 +734,21 @@ func (i *Instruction) String() string {
 					b.WriteString(" inverse")
 				}
 				if i.Predict {
-					b.WriteString(fmt.Sprintf(" predict %s", i.Pos()))
+					b.WriteString(fmt.Sprintf(" predict %s", i.Inst.Pos()))
 				}
 
 			case OpCall:
 				if i.Name != "" {
 					b.WriteString(i.Name)
 				} else if i.Inst != nil {
-					b.WriteString(fmt.Sprintf("%s", i.Pos()))
+					b.WriteString(i.Inst.Pos())
 				}
-				if i.ClusterID > 0 {
-					b.WriteString(fmt.Sprintf(" %d %s", i.ClusterID, i.ClusterType.String()))
+				if i.Cluster != nil {
+					b.WriteString(fmt.Sprintf(" %s %s", i.Cluster.Pos(), i.ClusterType.String()))
 				}
 
 			case OpJump:
-				b.WriteString(fmt.Sprintf("%s", i.Pos()))
+				b.WriteString(i.Inst.Pos())
 
 			case OpClone:
 				for i, inst := range i.Insts {
@@ -399,12 +762,21 @@ func (i *Instruction) String() string {
 				b.WriteString((*i.InstP).Pos())
 
 			}
+			if i.file != "" {
+				b.WriteString(" at " + i.Pos())
+			}
 			return b.String()
 		}(),
 	)
 }
 
+// Pos returns file:line of the constructor caller, or the address if not recorded
 func (i *Instruction) Pos() string {
-	//TODO show file and line
+	if i == nil {
+		return "nil"
+	}
+	if i.file != "" {
+		return fmt.Sprintf("%s:%d", filepath.Base(i.file), i.line)
+	}
 	return fmt.Sprintf("%p", i)
 }
@@ -1,6 +1,7 @@
 package pav
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -557,7 +558,6 @@ func TestShortest(t *testing.T) {
 							},
 						},
 					},
-					ClusterID:   1,
 					ClusterType: ClusterShortest,
 				},
 			},
@@ -595,7 +595,6 @@ func TestShortest2(t *testing.T) {
 								},
 							},
 						},
-						ClusterID:   1,
 						ClusterType: ClusterShortest,
 						Next: &Instruction{
 							Op:   OpRune,
@@ -757,3 +756,310 @@ func TestLeftRecursion2(t *testing.T) {
 		}
 	}
 }
+
+func TestPredictFork(t *testing.T) {
+	for _, input := range []string{
+		"ac",
+		"abc",
+	} {
+		vm := &VM{
+			Threads: []*Thread{
+				{
+					PC: Seq(
+						RunePredict(
+							Rune('a'),
+							Longest(
+								Literal("a"),
+								Literal("ab"),
+							),
+						),
+						Rune('c'),
+					),
+				},
+			},
+		}
+		eq(t,
+			match(vm, input), true,
+		)
+	}
+}
+
+func TestStepPeek(t *testing.T) {
+	run := func(obj interface{}, start string, input string, peek bool) (matched []bool, spawns int) {
+		vm := NewVMFromObject(obj, Named(start))
+		vm.Tracer = TracerFunc(func(ev TraceEvent) {
+			if ev.Kind == TraceSpawn {
+				spawns++
+			}
+		})
+		runes := []rune(input)
+		for i, r := range runes {
+			var res StepResult
+			if peek {
+				var next rune
+				if i+1 < len(runes) {
+					next = runes[i+1]
+				}
+				res = vm.StepPeek(r, next, i+1 < len(runes))
+			} else {
+				res = vm.Step(r)
+			}
+			matched = append(matched, len(res.Matched) > 0)
+		}
+		return
+	}
+
+	for _, c := range []struct {
+		obj   interface{}
+		start string
+		input string
+		ok    bool
+	}{
+		{new(JSONParser), "Text", `{"a": [1, -2.5e3, true, null, "é"]}` + "\n", true},
+		{new(JSONParser), "Text", `[1, 2,]` + "\n", false},
+		{new(JSON5Parser), "Text", `{a: 'b', c: +Infinity, /* d */ e: [0x1F,],}` + "\n", true},
+		{new(GoLexer), "Token", "0x1F", true},
+		{new(GoLexer), "Program", "package main\n\nfunc main() {\n\tx := 1 << 2 &^ 3\n\tfmt.Println(`x`, x, 'y', 1.5e-3i)\n}", true},
+	} {
+		matched, spawns := run(c.obj, c.start, c.input, false)
+		peekMatched, peekSpawns := run(c.obj, c.start, c.input, true)
+		eq(t,
+			fmt.Sprintf("%v", peekMatched), fmt.Sprintf("%v", matched),
+			peekMatched[len(peekMatched)-1], c.ok,
+		)
+		if peekSpawns >= spawns {
+			t.Fatalf("%T: not pruned: %d %d", c.obj, peekSpawns, spawns)
+		}
+	}
+}
+
+func TestStepPeekEOF(t *testing.T) {
+	vm := NewVM(nil, Seq(Rune('a'), Longest(Literal("b"), Literal("bc"), Optional(Rune('d')))))
+	res := vm.StepPeek('a', 0, false)
+	eq(t,
+		len(res.Matched), 1,
+		len(vm.Threads), 0,
+	)
+}
+
+var benchGoSource = []rune(strings.Repeat("func main() {\n\tx := 1 << 2 &^ 3\n\tfmt.Println(`x`, x, 'y', 1.5e-3i)\n}\n", 8))
+
+func BenchmarkGoLexerStep(b *testing.B) {
+	routines := ObjectRoutines(new(GoLexer))
+	vm := NewVM(routines, Named("Program"))
+	for i := 0; i < b.N; i++ {
+		vm.Reset(Named("Program"))
+		for _, r := range benchGoSource {
+			vm.Step(r)
+		}
+	}
+}
+
+func BenchmarkGoLexerStepPeek(b *testing.B) {
+	routines := ObjectRoutines(new(GoLexer))
+	vm := NewVM(routines, Named("Program"))
+	for i := 0; i < b.N; i++ {
+		vm.Reset(Named("Program"))
+		for j, r := range benchGoSource {
+			var next rune
+			if j+1 < len(benchGoSource) {
+				next = benchGoSource[j+1]
+			}
+			vm.StepPeek(r, next, j+1 < len(benchGoSource))
+		}
+	}
+}
+
+func TestStepAllocs(t *testing.T) {
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	element := []rune(`{"foo": [1, -2.5e3, "bar\n", true, null], "baz": {}}, `)
+	vm.Step('[')
+	for i := 0; i < 16; i++ {
+		for _, r := range element {
+			vm.Step(r)
+		}
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, r := range element {
+			vm.Step(r)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("%v allocations per element", allocs)
+	}
+	if len(vm.Threads) == 0 {
+		t.Fatal("should not stop")
+	}
+}
+
+func BenchmarkStepJSON(b *testing.B) {
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	element := []rune(`{"foo": [1, -2.5e3, "bar\n", true, null], "baz": {}}, `)
+	vm.Step('[')
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range element {
+			vm.Step(r)
+		}
+	}
+}
+
+func BenchmarkNestedJSON(b *testing.B) {
+	for _, depth := range []int{16, 256, 4096} {
+		input := []rune(strings.Repeat(`{"a": [`, depth) + "1" + strings.Repeat("]}", depth))
+		b.Run(fmt.Sprintf("depth-%d", depth), func(b *testing.B) {
+			vm := NewVMFromObject(new(JSONParser), nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vm.Reset(Named("Text"))
+				var res StepResult
+				for _, r := range input {
+					res = vm.Step(r)
+				}
+				if len(res.Matched) == 0 {
+					b.Fatal("should match")
+				}
+			}
+		})
+	}
+}
+
+func TestNestedStack(t *testing.T) {
+	depth := 1000
+	input := strings.Repeat(`{"a": [`, depth) + "1, 2" + strings.Repeat("]}", depth)
+	vm := NewVMFromObject(new(JSONParser), Named("Text"))
+	eq(t,
+		match(vm, input), true,
+	)
+	vm.Reset(Named("Text"))
+	eq(t,
+		match(vm, input[:len(input)-1]+"]"), false,
+	)
+	// all frames are released
+	vm.Reset(nil)
+	free := len(vm.frames)
+	vm.Reset(Named("Text"))
+	match(vm, input[:len(input)/2])
+	vm.Reset(nil)
+	eq(t,
+		len(vm.frames), free,
+	)
+}
+
+func TestTailCall(t *testing.T) {
+	// calls returning to nothing push no frame, so loops run in constant stack depth
+	vm := NewVM(nil, Seq(Rune('['), ZeroOrMore(Rune('a')), Rune(']')))
+	vm.Step('[')
+	for i := 0; i < 1000; i++ {
+		vm.Step('a')
+		for _, thread := range vm.Threads {
+			depth := 0
+			for f := thread.Stack; f != nil; f = f.Parent {
+				depth++
+			}
+			if depth > 2 {
+				t.Fatalf("stack depth %d after %d iterations", depth, i+1)
+			}
+		}
+	}
+	res := vm.Step(']')
+	eq(t,
+		len(res.Matched), 1,
+	)
+}
+
+func TestTailCallFrames(t *testing.T) {
+	// frames of tail calls are kept if they are observable
+	routines := map[string]Routine{
+		"A": {Start: Seq(Rune('x'), Named("B"))},
+		"B": {Start: Rune('y')},
+	}
+
+	// captures
+	vm := NewVM(routines, Named("A"))
+	vm.Capture = true
+	vm.Step('x')
+	res := vm.Step('y')
+	eq(t,
+		len(res.Matched), 1,
+	)
+	node := res.Matched[0].Tree().Find("A")
+	eq(t,
+		node != nil, true,
+		len(node.Children), 1,
+		node.Children[0].Name, "B",
+		node.Children[0].Start, 1,
+		node.Children[0].End, 2,
+	)
+
+	// returns of traced calls
+	var returns []string
+	vm = NewVM(routines, Named("A"))
+	vm.Tracer = TracerFunc(func(ev TraceEvent) {
+		if ev.Kind == TraceReturn {
+			returns = append(returns, ev.Name)
+		}
+	})
+	eq(t,
+		match(vm, "xy"), true,
+		returns, []string{"B", "A"},
+	)
+
+	// clusters
+	inst := Seq(Rune('a'), Shortest(Literal("b"), Literal("bc")))
+	eq(t,
+		match(NewVM(nil, inst), "ab"), true,
+		match(NewVM(nil, inst), "abc"), false,
+	)
+}
+
+func TestCloneMatch(t *testing.T) {
+	// forked threads returning without consuming runes match
+	inst := Seq(Rune('a'), Longest(Rune('b'), Optional(Rune('c')), emptyInstruction()))
+	for input, ok := range map[string]bool{
+		"a":  true,
+		"ab": true,
+		"ac": true,
+		"ad": false,
+	} {
+		eq(t,
+			match(NewVM(nil, inst), input), ok,
+		)
+	}
+}
+
+func TestKillUnwind(t *testing.T) {
+	// killed threads unwind their stacks, so Shortest clusters of matched threads are killed
+	for _, c := range []struct {
+		input string
+		ok    bool
+	}{
+		{"go `a`", true},
+		{"go`a`", false},
+		{"1.5`a`", false},
+	} {
+		vm := NewVMFromObject(new(GoLexer), Named("Program"))
+		eq(t,
+			match(vm, c.input), c.ok,
+		)
+	}
+
+	// and record captures of the unwound calls
+	vm := NewVM(map[string]Routine{
+		"A": {Start: Seq(Rune('a'), Rune('b'))},
+	}, Named("A"))
+	vm.Capture = true
+	vm.Step('a')
+	res := vm.Step('c')
+	eq(t,
+		len(res.Failed), 1,
+	)
+	node := res.Failed[0].Tree().Find("A")
+	eq(t,
+		node != nil, true,
+		node.Start, 0,
+		node.End, 1,
+	)
+}